@@ -2,21 +2,12 @@ package graphql
 
 import (
 	"fmt"
-	"strings"
 )
 
 type Arguments map[string]Value
 
 func (a Arguments) String() string {
-	if a == nil {
-		return ""
-	}
-	var t []string
-
-	for k, v := range a {
-		t = append(t, k+":"+v.String())
-	}
-	return "(" + strings.Join(t, " ") + ")"
+	return PrintCompact(a)
 }
 
 func (p *Parser) parseArguments() (Arguments, error) {
@@ -26,6 +17,9 @@ func (p *Parser) parseArguments() (Arguments, error) {
 	if err := p.nextNotSpace(); err != nil {
 		return nil, unexpected(err)
 	}
+	if p.cur() == ')' {
+		return nil, fmt.Errorf("arguments must not be empty")
+	}
 
 	args := make(Arguments)
 
@@ -0,0 +1,208 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client sends GraphQL requests to a remote endpoint over HTTP.
+type Client struct {
+	Endpoint   string
+	HTTP       *http.Client  // if nil, http.DefaultClient is used
+	RetryDelay time.Duration // initial delay before re-establishing a subscription, defaults to 1s
+	Transport  Transport     // protocol used by Subscribe, defaults to TransportSSE
+}
+
+// Request is a GraphQL request as sent over HTTP.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// Response is a GraphQL response as received over HTTP.
+type Response struct {
+	Data       json.RawMessage `json:"data,omitempty"`
+	Errors     Errors          `json:"errors,omitempty"`
+	Extensions map[string]any  `json:"extensions,omitempty"`
+}
+
+// Location is a position in a GraphQL document, as reported in errors.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error is a single entry of the errors array of a GraphQL response.
+type Error struct {
+	Message    string         `json:"message"`
+	Locations  []Location     `json:"locations,omitempty"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if len(e.Path) == 0 {
+		return e.Message
+	}
+	var t []string
+	for _, p := range e.Path {
+		t = append(t, fmt.Sprint(p))
+	}
+	return strings.Join(t, ".") + ": " + e.Message
+}
+
+type Errors []*Error
+
+func (e Errors) Error() string {
+	var t []string
+	for _, err := range e {
+		t = append(t, err.Error())
+	}
+	return strings.Join(t, "; ")
+}
+
+// ResponseError is returned by Client when the server answered with a
+// non-empty errors array. Data holds whatever partial data came with it,
+// and has already been decoded into the caller's target when present. If
+// decoding the partial data failed, DecodeErr holds why.
+type ResponseError struct {
+	Errors    Errors
+	Data      json.RawMessage
+	DecodeErr error
+}
+
+func (e *ResponseError) Error() string {
+	if e.DecodeErr != nil {
+		return "graphql: " + e.Errors.Error() + " (failed to decode response data: " + e.DecodeErr.Error() + ")"
+	}
+	return "graphql: " + e.Errors.Error()
+}
+
+func (e *ResponseError) Unwrap() error {
+	return e.DecodeErr
+}
+
+// NewClient returns a client for the given endpoint. httpClient may be nil.
+func NewClient(endpoint string, httpClient *http.Client) *Client {
+	return &Client{Endpoint: endpoint, HTTP: httpClient}
+}
+
+// Query runs the given query string and decodes the response data into out.
+func (c *Client) Query(ctx context.Context, query string, variables map[string]any, out any) error {
+	return c.Do(ctx, &Request{Query: query, Variables: variables}, out)
+}
+
+// QueryDocument runs operation opName of doc and decodes the response data
+// into out. opName can be empty if doc holds a single operation.
+func (c *Client) QueryDocument(ctx context.Context, doc *Document, opName string, variables map[string]any, out any) error {
+	return c.Do(ctx, &Request{Query: PrintCompact(doc), OperationName: opName, Variables: variables}, out)
+}
+
+// Do sends req and decodes the response data into out, which must be a
+// pointer or nil. Response keys are matched as encoding/json does, so an
+// aliased field is found under its Alias rather than its Name.
+//
+// If the response carries errors, a *ResponseError is returned after the
+// partial data (if any) was decoded into out.
+func (c *Client) Do(ctx context.Context, req *Request, out any) error {
+	return c.do(ctx, req, func(data []byte) error {
+		if out == nil {
+			return nil
+		}
+		return json.Unmarshal(data, out)
+	})
+}
+
+// Exec builds an operation of type typ from the shape of v (see
+// NewOperation), runs it and decodes the response data back into v using the
+// same field mapping, so the query and its decode target cannot drift apart.
+func (c *Client) Exec(ctx context.Context, typ OperationType, v any, variables map[string]any) error {
+	op, err := NewOperation(typ, "", v, variables)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, &Request{Query: PrintCompact(op), Variables: variables}, func(data []byte) error {
+		return unmarshalStruct(data, v)
+	})
+}
+
+func (c *Client) do(ctx context.Context, req *Request, decode func([]byte) error) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	hreq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	hreq.Header.Set("Content-Type", "application/json")
+	hreq.Header.Set("Accept", "application/graphql-response+json, application/json")
+
+	h := c.HTTP
+	if h == nil {
+		h = http.DefaultClient
+	}
+	resp, err := h.Do(hreq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	res, err := readResponse(resp)
+	if err != nil {
+		return err
+	}
+
+	var derr error
+	if len(res.Data) > 0 && !bytes.Equal(res.Data, []byte("null")) {
+		derr = decode(res.Data)
+	}
+	if len(res.Errors) > 0 {
+		return &ResponseError{Errors: res.Errors, Data: res.Data, DecodeErr: derr}
+	}
+	if derr != nil {
+		return fmt.Errorf("failed to decode response data: %w", derr)
+	}
+	return nil
+}
+
+func readResponse(resp *http.Response) (*Response, error) {
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	ct, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch ct {
+	case "application/json", "application/graphql-response+json":
+	default:
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected HTTP status %s", resp.Status)
+		}
+		return nil, fmt.Errorf("unexpected response content type %q", ct)
+	}
+
+	res := &Response{}
+	if err := json.Unmarshal(buf, res); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected HTTP status %s", resp.Status)
+		}
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if res.Data == nil && res.Errors == nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected HTTP status %s", resp.Status)
+		}
+		return nil, errors.New("response has neither data nor errors")
+	}
+	return res, nil
+}
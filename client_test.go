@@ -0,0 +1,79 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KarpelesLab/graphql"
+)
+
+func TestClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if _, err := graphql.Parse(req.Query); err != nil {
+			t.Errorf("sent query %q does not parse: %s", req.Query, err)
+		}
+		w.Header().Set("Content-Type", "application/graphql-response+json")
+		switch req.OperationName {
+		case "BadPartial":
+			w.Write([]byte(`{"data":{"hero":{"name":42}},"errors":[{"message":"oops"}]}`))
+		case "Partial":
+			w.Write([]byte(`{"data":{"hero":{"name":"R2-D2"},"friend":null},"errors":[{"message":"not found","path":["friend"]}]}`))
+		default:
+			if req.Variables["id"] != "1000" {
+				t.Errorf("unexpected variables: %v", req.Variables)
+			}
+			w.Write([]byte(`{"data":{"hero":{"name":"Luke"}}}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := graphql.NewClient(srv.URL, srv.Client())
+
+	var res struct {
+		Hero struct {
+			Name string `json:"name"`
+		} `json:"hero"`
+	}
+	err := c.Query(context.Background(), `query ($id: ID!) { hero: human(id: $id) { name } }`, map[string]any{"id": "1000"}, &res)
+	if err != nil {
+		t.Fatalf("query failed: %s", err)
+	}
+	if res.Hero.Name != "Luke" {
+		t.Errorf("unexpected hero name %q", res.Hero.Name)
+	}
+
+	doc, err := graphql.Parse(`query Partial { hero { name } friend(name: "C-3PO") { name ... { id } } }
+query BadPartial { hero { name } }`)
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+	res.Hero.Name = ""
+	err = c.QueryDocument(context.Background(), doc, "Partial", nil, &res)
+	var rerr *graphql.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected a ResponseError, got %v", err)
+	}
+	if len(rerr.Errors) != 1 || rerr.Errors[0].Message != "not found" {
+		t.Errorf("unexpected errors: %v", rerr.Errors)
+	}
+	if res.Hero.Name != "R2-D2" {
+		t.Errorf("partial data was not decoded, got %q", res.Hero.Name)
+	}
+
+	err = c.QueryDocument(context.Background(), doc, "BadPartial", nil, &res)
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected a ResponseError, got %v", err)
+	}
+	if rerr.Errors[0].Message != "oops" || rerr.DecodeErr == nil {
+		t.Errorf("expected errors and a decode error, got %v", rerr)
+	}
+}
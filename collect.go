@@ -0,0 +1,161 @@
+package graphql
+
+import "fmt"
+
+// CollectedField is a group of fields sharing the same response key, as
+// returned by CollectFields.
+type CollectedField struct {
+	Key    string // alias, or name if the field has no alias
+	Fields []*Field
+}
+
+// CollectedFields lists fields in the order their response key was first
+// selected.
+type CollectedFields []*CollectedField
+
+// Get returns the fields selected under key, or nil.
+func (c CollectedFields) Get(key string) *CollectedField {
+	for _, f := range c {
+		if f.Key == key {
+			return f
+		}
+	}
+	return nil
+}
+
+// TypeApplies reports whether a fragment with the type condition typeCond
+// applies to objects of type objectType.
+type TypeApplies func(objectType, typeCond string) bool
+
+// CollectFields returns the fields of s that apply to an object of type
+// objectType, following the field collection algorithm of the spec:
+// selections excluded by @skip or @include are ignored, fragments are
+// followed once each when their type condition applies, and fields are
+// grouped by response key.
+//
+// There is no schema to tell which types implement an interface or belong
+// to a union, so applies is used to decide whether a type condition other
+// than objectType itself applies. If nil, only objectType does. If
+// objectType is empty, the type is unknown and all type conditions apply.
+func CollectFields(applies TypeApplies, objectType string, s SelectionSet, variables map[string]any, fragments map[string]*Fragment) (CollectedFields, error) {
+	c := &fieldCollector{
+		applies:   applies,
+		variables: variables,
+		fragments: fragments,
+		visited:   make(map[string]bool),
+	}
+	var res CollectedFields
+	if err := c.collect(objectType, s, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+type fieldCollector struct {
+	applies   TypeApplies
+	variables map[string]any
+	fragments map[string]*Fragment
+	visited   map[string]bool
+}
+
+func (c *fieldCollector) typeApplies(objectType string, cond *TypeCondition) bool {
+	if cond == nil || cond.NamedType == objectType || objectType == "" {
+		return true
+	}
+	if c.applies == nil {
+		return false
+	}
+	return c.applies(objectType, cond.NamedType)
+}
+
+func (c *fieldCollector) collect(objectType string, s SelectionSet, res *CollectedFields) error {
+	for _, sel := range s {
+		var dirs Directives
+		switch n := sel.(type) {
+		case *Field:
+			dirs = n.Directives
+		case *InlineFragment:
+			dirs = n.Directives
+		case *FragmentSpread:
+			dirs = n.Directives
+		}
+		ok, err := shouldInclude(dirs, c.variables)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		switch n := sel.(type) {
+		case *Field:
+			key := n.Alias
+			if key == "" {
+				key = n.Name
+			}
+			if g := res.Get(key); g != nil {
+				g.Fields = append(g.Fields, n)
+			} else {
+				*res = append(*res, &CollectedField{Key: key, Fields: []*Field{n}})
+			}
+		case *InlineFragment:
+			if !c.typeApplies(objectType, n.TypeCondition) {
+				continue
+			}
+			if err := c.collect(objectType, n.SelectionSet, res); err != nil {
+				return err
+			}
+		case *FragmentSpread:
+			if c.visited[n.Name] {
+				continue
+			}
+			c.visited[n.Name] = true
+			f, ok := c.fragments[n.Name]
+			if !ok {
+				return fmt.Errorf("unknown fragment %s", n.Name)
+			}
+			if !c.typeApplies(objectType, f.TypeCondition) {
+				continue
+			}
+			if err := c.collect(objectType, f.SelectionSet, res); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// shouldInclude evaluates the @skip and @include directives of a selection
+func shouldInclude(dirs Directives, variables map[string]any) (bool, error) {
+	for _, d := range dirs {
+		switch d.Directive {
+		case "skip", "include":
+			v, err := directiveCondition(d, variables)
+			if err != nil {
+				return false, err
+			}
+			if v == (d.Directive == "skip") {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// directiveCondition returns the value of the "if" argument of d
+func directiveCondition(d *Directive, variables map[string]any) (bool, error) {
+	switch v := d.Arguments["if"].(type) {
+	case BooleanValue:
+		return bool(v), nil
+	case *VariableValue:
+		b, ok := variables[v.Var].(bool)
+		if !ok {
+			return false, fmt.Errorf("variable $%s of @%s must be a Boolean", v.Var, d.Directive)
+		}
+		return b, nil
+	case nil:
+		return false, fmt.Errorf("@%s requires an if argument", d.Directive)
+	default:
+		return false, fmt.Errorf("if argument of @%s must be a Boolean, got %s", d.Directive, v)
+	}
+}
@@ -0,0 +1,70 @@
+package graphql_test
+
+import (
+	"testing"
+
+	"github.com/KarpelesLab/graphql"
+)
+
+func TestCollectFields(t *testing.T) {
+	doc, err := graphql.Parse(`{
+  name
+  id @skip(if: true)
+  friends @include(if: $friends) { name }
+  ... on Droid { primaryFunction }
+  ... on Character { alias: name }
+  ...Human
+  ...Human
+  ...Droid @skip(if: $friends)
+}
+fragment Human on Human { height name }
+fragment Droid on Droid { model }`)
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+	sl := doc.Operations[""].SelectionSet
+	vars := map[string]any{"friends": true}
+
+	res, err := graphql.CollectFields(nil, "Human", sl, vars, doc.Fragments)
+	if err != nil {
+		t.Fatalf("failed to collect fields: %s", err)
+	}
+	var keys []string
+	for _, f := range res {
+		keys = append(keys, f.Key)
+	}
+	if len(keys) != 3 || keys[0] != "name" || keys[1] != "friends" || keys[2] != "height" || len(res.Get("name").Fields) != 2 {
+		t.Errorf("unexpected fields for Human: %v", keys)
+	}
+
+	// Droid implements Character
+	applies := func(obj, cond string) bool { return cond == "Character" && (obj == "Droid" || obj == "Human") }
+	res, err = graphql.CollectFields(applies, "Droid", sl, map[string]any{"friends": false}, doc.Fragments)
+	if err != nil {
+		t.Fatalf("failed to collect fields: %s", err)
+	}
+	keys = nil
+	for _, f := range res {
+		keys = append(keys, f.Key)
+	}
+	if len(keys) != 4 || keys[0] != "name" || keys[1] != "primaryFunction" || keys[2] != "alias" || keys[3] != "model" {
+		t.Errorf("unexpected fields for Droid: %v", keys)
+	}
+
+	// an unknown object type accepts every type condition
+	res, err = graphql.CollectFields(nil, "", sl, vars, doc.Fragments)
+	if err != nil {
+		t.Fatalf("failed to collect fields: %s", err)
+	}
+	keys = nil
+	for _, f := range res {
+		keys = append(keys, f.Key)
+	}
+	if len(keys) != 5 || keys[0] != "name" || keys[1] != "friends" || keys[2] != "primaryFunction" || keys[3] != "alias" || keys[4] != "height" {
+		t.Errorf("unexpected fields for unknown type: %v", keys)
+	}
+
+	if _, err := graphql.CollectFields(nil, "Human", sl, nil, doc.Fragments); err == nil {
+		t.Errorf("expected an error for a missing @include variable")
+	}
+}
@@ -1,6 +1,6 @@
 package graphql
 
-import "strings"
+import "fmt"
 
 type Directive struct {
 	Directive string
@@ -8,19 +8,13 @@ type Directive struct {
 }
 
 func (d *Directive) String() string {
-	return "@" + d.Directive + " " + d.Arguments.String()
+	return PrintCompact(d)
 }
 
 type Directives []*Directive
 
 func (ds Directives) String() string {
-	var r []string
-
-	for _, d := range ds {
-		r = append(r, d.String())
-	}
-
-	return strings.Join(r, " ")
+	return PrintCompact(ds)
 }
 
 func (p *Parser) parseDirectives() (Directives, error) {
@@ -33,11 +27,17 @@ func (p *Parser) parseDirectives() (Directives, error) {
 		if err := p.next(); err != nil {
 			return nil, unexpected(err)
 		}
-		name := p.readName()
-		args, err := p.parseArguments()
-		if err != nil {
-			return nil, err
+		if !p.isName() {
+			return nil, fmt.Errorf("expected a directive name after @ but got a %c", p.cur())
+		}
+		d := &Directive{Directive: p.readName()}
+		if p.cur() == '(' {
+			args, err := p.parseArguments()
+			if err != nil {
+				return nil, err
+			}
+			d.Arguments = args
 		}
-		res = append(res, &Directive{Directive: name, Arguments: args})
+		res = append(res, d)
 	}
 }
@@ -1,7 +1,7 @@
 package graphql
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
 )
 
@@ -18,13 +18,75 @@ func newDocument() *Document {
 }
 
 func (d *Document) String() string {
-	buf := &bytes.Buffer{}
+	return Print(d)
+}
 
-	for _, v := range d.Operations {
-		fmt.Fprintf(buf, "%s\n\n", v.String())
+// operation returns the operation called name, or the only operation of d
+// if name is empty
+func (d *Document) operation(name string) (*Operation, error) {
+	if name == "" && len(d.Operations) != 1 {
+		if len(d.Operations) == 0 {
+			return nil, errors.New("document has no operation")
+		}
+		return nil, errors.New("operation name is required when a document has multiple operations")
+	}
+	if name == "" {
+		for _, op := range d.Operations {
+			return op, nil
+		}
 	}
-	for _, v := range d.Fragments {
-		fmt.Fprintf(buf, "%s\n\n", v.String())
+	op, ok := d.Operations[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown operation %s", name)
+	}
+	return op, nil
+}
+
+// fragmentsUsedBy returns the names of the fragments used by op, directly or
+// through other fragments, in name order
+func (d *Document) fragmentsUsedBy(op *Operation) ([]string, error) {
+	used := make(map[string]bool)
+	var todo []string
+	v := &Visitor{
+		EnterFragmentSpread: func(c *Cursor, f *FragmentSpread) WalkAction {
+			if !used[f.Name] {
+				used[f.Name] = true
+				todo = append(todo, f.Name)
+			}
+			return WalkContinue
+		},
+	}
+
+	Walk(v, op)
+	for len(todo) > 0 {
+		name := todo[0]
+		todo = todo[1:]
+		f, ok := d.Fragments[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown fragment %s", name)
+		}
+		Walk(v, f)
+	}
+	return sortedKeys(used), nil
+}
+
+// ExtractOperation returns a new document holding only the operation called
+// name and the fragments it uses, directly or through other fragments. If
+// name is empty, doc must contain a single operation.
+func (d *Document) ExtractOperation(name string) (*Document, error) {
+	op, err := d.operation(name)
+	if err != nil {
+		return nil, err
+	}
+	frags, err := d.fragmentsUsedBy(op)
+	if err != nil {
+		return nil, err
+	}
+
+	res := newDocument()
+	res.Operations[op.Name] = op
+	for _, f := range frags {
+		res.Fragments[f] = d.Fragments[f]
 	}
-	return buf.String()
+	return res, nil
 }
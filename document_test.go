@@ -0,0 +1,41 @@
+package graphql_test
+
+import (
+	"testing"
+
+	"github.com/KarpelesLab/graphql"
+)
+
+func TestExtractOperation(t *testing.T) {
+	doc, err := graphql.Parse(`query A { ...F }
+query B { b }
+fragment F on Query { a ...G }
+fragment G on Query { g }
+fragment H on Query { h }`)
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+
+	res, err := doc.ExtractOperation("A")
+	if err != nil {
+		t.Fatalf("failed to extract operation: %s", err)
+	}
+	expect := `query A {...F} fragment F on Query {a ...G} fragment G on Query {g}`
+	if s := graphql.PrintCompact(res); s != expect {
+		t.Errorf("unexpected extracted document:\n%s\nexpected:\n%s", s, expect)
+	}
+	if _, err := doc.ExtractOperation("C"); err == nil {
+		t.Errorf("expected an error for an unknown operation")
+	}
+	if _, err := doc.ExtractOperation(""); err == nil {
+		t.Errorf("expected an error for an ambiguous anonymous operation")
+	}
+
+	single, err := graphql.Parse(`{ a }`)
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+	if res, err := single.ExtractOperation(""); err != nil || len(res.Operations) != 1 {
+		t.Errorf("failed to extract anonymous operation: %v", err)
+	}
+}
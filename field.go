@@ -3,7 +3,6 @@ package graphql
 import (
 	"encoding/json"
 	"fmt"
-	"strings"
 )
 
 type Field struct {
@@ -15,24 +14,7 @@ type Field struct {
 }
 
 func (f *Field) String() string {
-	var t []string
-
-	if f.Alias == "" {
-		t = append(t, f.Name)
-	} else {
-		t = append(t, f.Alias+":", f.Name)
-	}
-
-	if v := f.Arguments.String(); v != "" {
-		t = append(t, v)
-	}
-	if v := f.Directives.String(); v != "" {
-		t = append(t, v)
-	}
-	if v := f.SelectionSet.String(); v != "" {
-		t = append(t, v)
-	}
-	return strings.Join(t, " ")
+	return PrintCompact(f)
 }
 
 func (f *Field) MarshalJSON() ([]byte, error) {
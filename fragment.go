@@ -3,7 +3,6 @@ package graphql
 import (
 	"encoding/json"
 	"fmt"
-	"strings"
 )
 
 type Fragment struct {
@@ -14,14 +13,7 @@ type Fragment struct {
 }
 
 func (f *Fragment) String() string {
-	vals := []string{
-		"fragment",
-		f.Name,
-		f.TypeCondition.String(),
-		f.Directives.String(),
-		f.SelectionSet.String(),
-	}
-	return strings.Join(vals, " ")
+	return PrintCompact(f)
 }
 
 type InlineFragment struct {
@@ -46,16 +38,16 @@ func (f *InlineFragment) MarshalJSON() ([]byte, error) {
 }
 
 func (i *InlineFragment) String() string {
-	return "..." + i.TypeCondition.String() + " " + i.Directives.String() + " " + i.SelectionSet.String()
+	return PrintCompact(i)
 }
 
 type FragmentSpread struct {
-	Name       string     `json:"selection_set"`
+	Name       string     `json:"name"`
 	Directives Directives `json:"directives,omitempty"`
 }
 
 func (f *FragmentSpread) String() string {
-	return "..." + f.Name + " " + f.Directives.String()
+	return PrintCompact(f)
 }
 
 func (f *FragmentSpread) MarshalJSON() ([]byte, error) {
@@ -88,6 +80,11 @@ func (p *Parser) readFragment() error {
 	}
 	f.TypeCondition = cond
 
+	f.Directives, err = p.parseDirectives()
+	if err != nil {
+		return err
+	}
+
 	sl, err := p.parseSelectionSet()
 	if err != nil {
 		return err
@@ -0,0 +1,124 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InlineFragments returns a copy of op where every FragmentSpread is
+// replaced with an InlineFragment holding the type condition, directives
+// and selections of the matching fragment of doc.
+//
+// Inline fragments that have no directives and either no type condition or
+// the same type condition as the fragment they are in are redundant, and
+// their selections are moved to the parent selection set. Without a schema
+// the type of fields is unknown, so this only happens inside fragments.
+//
+// An error is returned if a fragment is unknown or spreads itself, directly
+// or not.
+func InlineFragments(doc *Document, op *Operation) (*Operation, error) {
+	fi := &fragmentInliner{doc: doc}
+	sl, err := fi.selectionSet(op.SelectionSet, "")
+	if err != nil {
+		return nil, fmt.Errorf("in %s %s: %w", op.OperationType, op.Name, err)
+	}
+	res := *op
+	res.SelectionSet = sl
+	return &res, nil
+}
+
+// PruneFragments returns a copy of doc without the fragments that are not
+// used by any of its operations.
+func PruneFragments(doc *Document) (*Document, error) {
+	res := newDocument()
+	for name, op := range doc.Operations {
+		res.Operations[name] = op
+		used, err := doc.fragmentsUsedBy(op)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range used {
+			res.Fragments[f] = doc.Fragments[f]
+		}
+	}
+	return res, nil
+}
+
+type fragmentInliner struct {
+	doc   *Document
+	stack []string // fragments being inlined
+}
+
+// selectionSet returns s with fragments inlined. parentType is the type
+// condition s is applied to, or empty if unknown.
+func (fi *fragmentInliner) selectionSet(s SelectionSet, parentType string) (SelectionSet, error) {
+	if s == nil {
+		return nil, nil
+	}
+	res := SelectionSet{}
+
+	for _, sel := range s {
+		switch n := sel.(type) {
+		case *Field:
+			if n.SelectionSet == nil {
+				res = append(res, n)
+				continue
+			}
+			sub, err := fi.selectionSet(n.SelectionSet, "")
+			if err != nil {
+				return nil, err
+			}
+			f := *n
+			f.SelectionSet = sub
+			res = append(res, &f)
+		case *InlineFragment:
+			typ := parentType
+			if n.TypeCondition != nil {
+				typ = n.TypeCondition.NamedType
+			}
+			sub, err := fi.selectionSet(n.SelectionSet, typ)
+			if err != nil {
+				return nil, err
+			}
+			if len(n.Directives) == 0 && (n.TypeCondition == nil || typ == parentType) {
+				res = append(res, sub...)
+				continue
+			}
+			f := *n
+			f.SelectionSet = sub
+			res = append(res, &f)
+		case *FragmentSpread:
+			frag, ok := fi.doc.Fragments[n.Name]
+			if !ok {
+				return nil, fmt.Errorf("unknown fragment %s", n.Name)
+			}
+			for i, name := range fi.stack {
+				if name == n.Name {
+					return nil, fmt.Errorf("fragment cycle: %s -> %s", strings.Join(fi.stack[i:], " -> "), n.Name)
+				}
+			}
+			fi.stack = append(fi.stack, n.Name)
+			sub, err := fi.selectionSet(frag.SelectionSet, frag.TypeCondition.NamedType)
+			fi.stack = fi.stack[:len(fi.stack)-1]
+			if err != nil {
+				return nil, err
+			}
+
+			var dirs Directives
+			dirs = append(dirs, n.Directives...)
+			dirs = append(dirs, frag.Directives...)
+			if len(dirs) == 0 && frag.TypeCondition.NamedType == parentType {
+				res = append(res, sub...)
+				continue
+			}
+			res = append(res, &InlineFragment{
+				TypeCondition: &TypeCondition{NamedType: frag.TypeCondition.NamedType},
+				Directives:    dirs,
+				SelectionSet:  sub,
+			})
+		default:
+			res = append(res, sel)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,58 @@
+package graphql_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KarpelesLab/graphql"
+)
+
+func TestInlineFragments(t *testing.T) {
+	doc, err := graphql.Parse(`query Q {
+  hero { ...Char ...Droid @include(if: $d) }
+}
+fragment Char on Character { name ...Named ... on Character { id } }
+fragment Named on Character { name }
+fragment Droid on Droid { primaryFunction }
+fragment Unused on Character { id }
+query Loop { ...A }
+fragment A on Query { ...B }
+fragment B on Query { a { ...A } }`)
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+
+	op, err := graphql.InlineFragments(doc, doc.Operations["Q"])
+	if err != nil {
+		t.Fatalf("failed to inline fragments: %s", err)
+	}
+	expect := `query Q {hero {... on Character {name name id} ... on Droid @include(if: $d) {primaryFunction}}}`
+	if res := graphql.PrintCompact(op); res != expect {
+		t.Errorf("unexpected inlined operation:\n%s\nexpected:\n%s", res, expect)
+	}
+	if len(doc.Operations["Q"].SelectionSet[0].(*graphql.Field).SelectionSet) != 2 {
+		t.Errorf("original operation was modified")
+	}
+
+	_, err = graphql.InlineFragments(doc, doc.Operations["Loop"])
+	if err == nil || !strings.Contains(err.Error(), "fragment cycle: A -> B -> A") {
+		t.Errorf("expected a cycle error, got %v", err)
+	}
+
+	pruned, err := graphql.PruneFragments(doc)
+	if err != nil {
+		t.Fatalf("failed to prune fragments: %s", err)
+	}
+	if len(pruned.Fragments) != 5 || pruned.Fragments["Unused"] != nil || len(doc.Fragments) != 6 {
+		t.Errorf("unexpected fragments after pruning: %v", pruned.Fragments)
+	}
+
+	res, err := graphql.Normalize(doc, "Q", &graphql.NormalizeOptions{InlineFragments: true})
+	if err != nil {
+		t.Fatalf("failed to normalize: %s", err)
+	}
+	expect = `query Q{hero{...on Character{id name name}...on Droid@include(if:$d){primaryFunction}}}`
+	if res != expect {
+		t.Errorf("unexpected normalized operation:\n%s\nexpected:\n%s", res, expect)
+	}
+}
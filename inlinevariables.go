@@ -0,0 +1,267 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// InlineVariables returns a document holding operation opName of doc and the
+// fragments it uses, where each variable is replaced with its value from
+// vars written as a literal, and variable definitions are removed.
+//
+// Values are coerced to the type of their variable definition: Int, Float,
+// String, Boolean and ID are checked, lists accept a single item, variables
+// missing from vars take their default value, and arguments using a missing
+// nullable variable without default are removed, except in directives where
+// this is an error. Other types are not known without a schema and are
+// converted as they are, so an enum value should be passed as an EnumValue
+// to be written as such.
+func InlineVariables(doc *Document, opName string, vars map[string]any) (*Document, error) {
+	sub, err := doc.ExtractOperation(opName)
+	if err != nil {
+		return nil, err
+	}
+	var op *Operation
+	for _, o := range sub.Operations {
+		op = o
+	}
+
+	values := make(map[string]Value)
+	absent := make(map[string]bool)
+	for _, def := range op.VariableDefinitions {
+		v, ok := vars[def.Variable]
+		if !ok {
+			switch {
+			case def.DefaultValue != nil:
+				values[def.Variable] = def.DefaultValue
+			case strings.HasSuffix(def.Type, "!"):
+				return nil, fmt.Errorf("missing value for required variable $%s of type %s", def.Variable, def.Type)
+			default:
+				absent[def.Variable] = true
+			}
+			continue
+		}
+		val, err := coerceVariable(def.Type, v)
+		if err != nil {
+			return nil, fmt.Errorf("variable $%s: %w", def.Variable, err)
+		}
+		values[def.Variable] = val
+	}
+
+	var rerr error
+	res := Rewrite(sub, &Rewriter{
+		Operation: func(c *Cursor, op *Operation) *Operation {
+			n := *op
+			n.VariableDefinitions = nil
+			return &n
+		},
+		Value: func(c *Cursor, v Value) Value {
+			vv, ok := v.(*VariableValue)
+			if !ok {
+				return v
+			}
+			if val, ok := values[vv.Var]; ok {
+				return val
+			}
+			if !absent[vv.Var] {
+				if rerr == nil {
+					rerr = fmt.Errorf("variable $%s is not defined", vv.Var)
+				}
+				return v
+			}
+			if _, ok := c.Parent().(ListValue); ok {
+				// lists can't have holes
+				return NullValue{}
+			}
+			if a := c.Ancestors(); len(a) >= 2 {
+				if d, ok := a[len(a)-2].(*Directive); ok {
+					// removing the argument would change what the
+					// directive means, or make it invalid like @include
+					if rerr == nil {
+						rerr = fmt.Errorf("variable $%s has no value and is used by directive @%s", vv.Var, d.Directive)
+					}
+					return v
+				}
+			}
+			// remove argument or object field
+			return nil
+		},
+	})
+	if rerr != nil {
+		return nil, rerr
+	}
+	return res, nil
+}
+
+// coerceVariable returns v as a literal of type typ
+func coerceVariable(typ string, v any) (Value, error) {
+	nonNull := strings.HasSuffix(typ, "!")
+	typ = strings.TrimSuffix(typ, "!")
+
+	if _, ok := v.(NullValue); v == nil || ok {
+		if nonNull {
+			return nil, fmt.Errorf("null is not allowed for type %s!", typ)
+		}
+		return NullValue{}, nil
+	}
+
+	if strings.HasPrefix(typ, "[") && strings.HasSuffix(typ, "]") {
+		sub := typ[1 : len(typ)-1]
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			// input coercion of lists accepts a single item
+			item, err := coerceVariable(sub, v)
+			if err != nil {
+				return nil, err
+			}
+			return ListValue{item}, nil
+		}
+		res := make(ListValue, rv.Len())
+		for i := range res {
+			item, err := coerceVariable(sub, rv.Index(i).Interface())
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+			res[i] = item
+		}
+		return res, nil
+	}
+
+	val, err := valueOf(v)
+	if err != nil {
+		return nil, err
+	}
+
+	switch typ {
+	case "Int":
+		switch n := val.(type) {
+		case IntValue:
+			if _, err := strconv.ParseInt(string(n), 10, 32); err != nil {
+				return nil, fmt.Errorf("%s is out of range for Int", n)
+			}
+			return n, nil
+		}
+	case "Float":
+		switch n := val.(type) {
+		case IntValue:
+			return FloatValue(n + ".0"), nil
+		case FloatValue:
+			return n, nil
+		}
+	case "String", "Boolean":
+		if _, ok := val.(StringValue); ok && typ == "String" {
+			return val, nil
+		}
+		if _, ok := val.(BooleanValue); ok && typ == "Boolean" {
+			return val, nil
+		}
+	case "ID":
+		switch val.(type) {
+		case StringValue, IntValue:
+			return val, nil
+		}
+	default:
+		return val, nil
+	}
+	return nil, fmt.Errorf("expected a value of type %s, got %T", typ, v)
+}
+
+// valueOf converts a Go value, such as one decoded from JSON, into a Value
+func valueOf(v any) (Value, error) {
+	if v == nil {
+		return NullValue{}, nil
+	}
+
+	switch n := v.(type) {
+	case StringValue, IntValue, FloatValue, BooleanValue, EnumValue, NullValue, ListValue, ObjectValue:
+		// already a literal, other Value types such as fmt.Stringer
+		// implementations are converted below
+		return n.(Value), nil
+	case json.Number:
+		// keep the number as written
+		p := &Parser{str: string(n)}
+		val, err := p.parseNumberValue()
+		if err != nil {
+			return nil, err
+		}
+		if !p.eof() {
+			return nil, fmt.Errorf("invalid number %s", n)
+		}
+		return val, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return BooleanValue(rv.Bool()), nil
+	case reflect.String:
+		return StringValue(rv.String()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return IntValue(strconv.FormatInt(rv.Int(), 10)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return IntValue(strconv.FormatUint(rv.Uint(), 10)), nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("%v is not a valid Float", f)
+		}
+		if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+			// JSON numbers are decoded as float64
+			return IntValue(strconv.FormatInt(int64(f), 10)), nil
+		}
+		res := strconv.FormatFloat(f, 'g', -1, 64)
+		if !strings.ContainsAny(res, ".e") {
+			// make sure this isn't read back as an IntValue
+			res += ".0"
+		}
+		return FloatValue(res), nil
+	case reflect.Slice, reflect.Array:
+		res := make(ListValue, rv.Len())
+		for i := range res {
+			item, err := valueOf(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			res[i] = item
+		}
+		return res, nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String {
+			res := make(ObjectValue)
+			iter := rv.MapRange()
+			for iter.Next() {
+				item, err := valueOf(iter.Value().Interface())
+				if err != nil {
+					return nil, err
+				}
+				res[iter.Key().String()] = item
+			}
+			return res, nil
+		}
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return NullValue{}, nil
+		}
+		if rv.Kind() == reflect.Interface {
+			return valueOf(rv.Elem().Interface())
+		}
+	}
+
+	// anything else (structs, pointers, ...) goes through its JSON form
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	var res any
+	if err := dec.Decode(&res); err != nil {
+		return nil, err
+	}
+	return valueOf(res)
+}
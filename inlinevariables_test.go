@@ -0,0 +1,91 @@
+package graphql_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KarpelesLab/graphql"
+)
+
+func TestInlineVariables(t *testing.T) {
+	doc, err := graphql.Parse(`query Q($id: ID!, $first: Int = 10, $tags: [String!], $after: String, $filter: Filter, $ep: Episode, $ratio: Float!) {
+  user(id: $id) {
+    ...Posts
+    ratio(value: $ratio)
+  }
+  hero(episode: $ep) { name }
+}
+fragment Posts on User { posts(first: $first, after: $after, tags: $tags, filter: $filter) { title } }
+query Other { other }`)
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+
+	var vars map[string]any
+	json.Unmarshal([]byte(`{"id": "42", "tags": "go", "filter": {"min": 1, "name": "a\"b"}, "ratio": 2}`), &vars)
+	vars["ep"] = graphql.EnumValue("JEDI")
+
+	res, err := graphql.InlineVariables(doc, "Q", vars)
+	if err != nil {
+		t.Fatalf("failed to inline variables: %s", err)
+	}
+	expect := `query Q {user(id: "42") {...Posts ratio(value: 2.0)} hero(episode: JEDI) {name}} fragment Posts on User {posts(filter: {min: 1, name: "a\"b"}, first: 10, tags: ["go"]) {title}}`
+	if s := graphql.PrintCompact(res); s != expect {
+		t.Errorf("unexpected document:\n%s\nexpected:\n%s", s, expect)
+	}
+	if len(doc.Operations["Q"].VariableDefinitions) != 7 {
+		t.Errorf("original document was modified")
+	}
+
+	_, err = graphql.InlineVariables(doc, "Q", map[string]any{"ratio": 1})
+	if err == nil || !strings.Contains(err.Error(), "$id") {
+		t.Errorf("expected an error for missing $id, got %v", err)
+	}
+	_, err = graphql.InlineVariables(doc, "Q", map[string]any{"id": "1", "ratio": 1, "first": "ten"})
+	if err == nil || !strings.Contains(err.Error(), "$first") {
+		t.Errorf("expected an error for invalid $first, got %v", err)
+	}
+
+	doc, err = graphql.Parse(`query Q($x: Boolean) { a @include(if: $x) }`)
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+	_, err = graphql.InlineVariables(doc, "Q", nil)
+	if err == nil || !strings.Contains(err.Error(), "@include") {
+		t.Errorf("expected an error for absent $x in @include, got %v", err)
+	}
+	res, err = graphql.InlineVariables(doc, "Q", map[string]any{"x": false})
+	if err != nil || graphql.PrintCompact(res) != `query Q {a @include(if: false)}` {
+		t.Errorf("unexpected result %s (%v)", graphql.PrintCompact(res), err)
+	}
+
+	// json.Number and Value implementations go through the same checks as
+	// other values
+	doc, err = graphql.Parse(`query Q($n: Int!, $at: DateTime, $s: String, $l: [Int]) { a(n: $n, at: $at, s: $s, l: $l) }`)
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+	_, err = graphql.InlineVariables(doc, "Q", map[string]any{"n": json.Number("99999999999")})
+	if err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("expected an out of range error for $n, got %v", err)
+	}
+	_, err = graphql.InlineVariables(doc, "Q", map[string]any{"n": 1, "s": graphql.EnumValue("A")})
+	if err == nil || !strings.Contains(err.Error(), "$s") {
+		t.Errorf("expected an error for an enum value as String, got %v", err)
+	}
+	res, err = graphql.InlineVariables(doc, "Q", map[string]any{
+		"n":  json.Number("7"),
+		"at": time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		"s":  graphql.StringValue("x"),
+		"l":  graphql.ListValue{graphql.IntValue("1"), graphql.NullValue{}},
+	})
+	expect = `query Q {a(at: "2024-01-01T00:00:00Z", l: [1, null], n: 7, s: "x")}`
+	if err != nil || graphql.PrintCompact(res) != expect {
+		t.Errorf("unexpected result %s (%v)", graphql.PrintCompact(res), err)
+	}
+	if _, err := graphql.Parse(graphql.PrintCompact(res)); err != nil {
+		t.Errorf("inlined document does not parse: %s", err)
+	}
+}
@@ -0,0 +1,194 @@
+package graphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+)
+
+// NormalizeOptions controls what Normalize changes besides ordering.
+type NormalizeOptions struct {
+	HideLiterals    bool // replace literal values with placeholders: 0, "", [] and {}
+	RemoveAliases   bool // drop field aliases
+	InlineFragments bool // replace fragment spreads with inline fragments
+}
+
+// Normalize returns operation opName of doc in a deterministic form, so that
+// equivalent operations give the same string. Only the operation and the
+// fragments it uses are kept (unless they are inlined, see
+// InlineFragments), selections are sorted by kind then name, arguments and
+// variable definitions by name, and directives of fragments by name. As with
+// Apollo, directives of operations and fields keep their order. The result
+// is printed on a single line with no whitespace other than what is needed
+// to separate names.
+//
+// opName can be empty if doc has a single operation.
+func Normalize(doc *Document, opName string, opts *NormalizeOptions) (string, error) {
+	if opts == nil {
+		opts = &NormalizeOptions{}
+	}
+	sub, err := doc.ExtractOperation(opName)
+	if err != nil {
+		return "", err
+	}
+	if opts.InlineFragments {
+		for name, op := range sub.Operations {
+			if sub.Operations[name], err = InlineFragments(sub, op); err != nil {
+				return "", err
+			}
+		}
+		sub.Fragments = make(map[string]*Fragment)
+	}
+	sub = Rewrite(sub, normalizer(opts))
+
+	// fragments go first, as in the Apollo signature where definitions are
+	// sorted by kind
+	pr := &printer{mode: printReduced}
+	for _, name := range sortedKeys(sub.Fragments) {
+		pr.print(sub.Fragments[name])
+	}
+	for _, op := range sub.Operations {
+		pr.print(op)
+	}
+	return pr.buf.String(), nil
+}
+
+// OperationSignature returns the signature of operation opName of doc, as
+// computed by Apollo's usage reporting: the normalized operation with
+// literals hidden and aliases removed.
+func OperationSignature(doc *Document, opName string) (string, error) {
+	return Normalize(doc, opName, &NormalizeOptions{HideLiterals: true, RemoveAliases: true})
+}
+
+// OperationSignatureHash returns the hex encoded SHA-256 hash of the
+// signature of operation opName of doc.
+func OperationSignatureHash(doc *Document, opName string) (string, error) {
+	sig, err := OperationSignature(doc, opName)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256([]byte(sig))
+	return hex.EncodeToString(h[:]), nil
+}
+
+func normalizer(opts *NormalizeOptions) *Rewriter {
+	r := &Rewriter{
+		Operation: func(c *Cursor, op *Operation) *Operation {
+			defs := sortedVariableDefinitions(op.VariableDefinitions)
+			if sameNode(defs, op.VariableDefinitions) {
+				return op
+			}
+			n := *op
+			n.VariableDefinitions = defs
+			return &n
+		},
+		Fragment: func(c *Cursor, f *Fragment) *Fragment {
+			if dirs := sortedDirectives(f.Directives); !sameNode(dirs, f.Directives) {
+				n := *f
+				n.Directives = dirs
+				return &n
+			}
+			return f
+		},
+		SelectionSet: func(c *Cursor, s SelectionSet) SelectionSet {
+			if sort.SliceIsSorted(s, func(i, j int) bool { return selectionLess(s[i], s[j]) }) {
+				return s
+			}
+			res := append(SelectionSet(nil), s...)
+			sort.SliceStable(res, func(i, j int) bool { return selectionLess(res[i], res[j]) })
+			return res
+		},
+		Selection: func(c *Cursor, sel Selection) Selection {
+			switch s := sel.(type) {
+			case *Field:
+				if s.Alias == "" || !opts.RemoveAliases {
+					return s
+				}
+				n := *s
+				n.Alias = ""
+				return &n
+			case *InlineFragment:
+				if dirs := sortedDirectives(s.Directives); !sameNode(dirs, s.Directives) {
+					n := *s
+					n.Directives = dirs
+					return &n
+				}
+			case *FragmentSpread:
+				if dirs := sortedDirectives(s.Directives); !sameNode(dirs, s.Directives) {
+					n := *s
+					n.Directives = dirs
+					return &n
+				}
+			}
+			return sel
+		},
+	}
+	if opts.HideLiterals {
+		r.Value = hideLiteral
+	}
+	return r
+}
+
+// hideLiteral replaces literal values with a placeholder of the same kind.
+// Floats become 0 just like integers, as done by Apollo.
+func hideLiteral(c *Cursor, v Value) Value {
+	switch v.(type) {
+	case IntValue, FloatValue:
+		return IntValue("0")
+	case StringValue:
+		return StringValue("")
+	case ListValue:
+		return ListValue{}
+	case ObjectValue:
+		return ObjectValue{}
+	}
+	return v
+}
+
+// selectionKind returns the order of the kind of s when sorting selections,
+// which is the alphabetical order of the kind names
+func selectionKind(s Selection) int {
+	switch s.(type) {
+	case *Field:
+		return 0
+	case *FragmentSpread:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func selectionLess(a, b Selection) bool {
+	ka, kb := selectionKind(a), selectionKind(b)
+	if ka != kb {
+		return ka < kb
+	}
+	switch a := a.(type) {
+	case *Field:
+		return a.Name < b.(*Field).Name
+	case *FragmentSpread:
+		return a.Name < b.(*FragmentSpread).Name
+	}
+	// inline fragments keep their order
+	return false
+}
+
+func sortedDirectives(ds Directives) Directives {
+	less := func(a, b *Directive) bool { return a.Directive < b.Directive }
+	if sort.SliceIsSorted(ds, func(i, j int) bool { return less(ds[i], ds[j]) }) {
+		return ds
+	}
+	res := append(Directives(nil), ds...)
+	sort.SliceStable(res, func(i, j int) bool { return less(res[i], res[j]) })
+	return res
+}
+
+func sortedVariableDefinitions(defs VariableDefinitions) VariableDefinitions {
+	less := func(a, b *VariableDefinition) bool { return a.Variable < b.Variable }
+	if sort.SliceIsSorted(defs, func(i, j int) bool { return less(defs[i], defs[j]) }) {
+		return defs
+	}
+	res := append(VariableDefinitions(nil), defs...)
+	sort.SliceStable(res, func(i, j int) bool { return less(res[i], res[j]) })
+	return res
+}
@@ -0,0 +1,85 @@
+package graphql_test
+
+import (
+	"testing"
+
+	"github.com/KarpelesLab/graphql"
+)
+
+func TestOperationSignature(t *testing.T) {
+	doc, err := graphql.Parse(`query Foo($b: Int, $a: Boolean) {
+  user(name: "hello", age: 5) {
+    ...Bar
+    ... on User {
+      hello
+      bee
+    }
+    tz
+    aliased: name
+  }
+}
+
+fragment Unused on User { id }
+
+fragment Bar on User {
+  age @skip(if: $a)
+  ...Nested
+}
+
+fragment Nested on User {
+  blah
+}
+
+query Other { other }`)
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+
+	sig, err := graphql.OperationSignature(doc, "Foo")
+	if err != nil {
+		t.Fatalf("failed to compute signature: %s", err)
+	}
+	expect := `fragment Bar on User{age@skip(if:$a)...Nested}fragment Nested on User{blah}query Foo($a:Boolean,$b:Int){user(age:0,name:""){name tz...Bar...on User{bee hello}}}`
+	if sig != expect {
+		t.Errorf("unexpected signature:\n%s\nexpected:\n%s", sig, expect)
+	}
+
+	res, err := graphql.Normalize(doc, "Foo", nil)
+	if err != nil {
+		t.Fatalf("failed to normalize: %s", err)
+	}
+	expect = `fragment Bar on User{age@skip(if:$a)...Nested}fragment Nested on User{blah}query Foo($a:Boolean,$b:Int){user(age:5,name:"hello"){aliased:name tz...Bar...on User{bee hello}}}`
+	if res != expect {
+		t.Errorf("unexpected normalized operation:\n%s\nexpected:\n%s", res, expect)
+	}
+
+	// directives are only sorted on fragments, as done by Apollo
+	dirs, err := graphql.Parse(`query Q @z @a { a @z @a ...F @z @a ... on T @z @a { b } } fragment F on T @z @a { c }`)
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+	sig, err = graphql.OperationSignature(dirs, "Q")
+	expect = `fragment F on T@a@z{c}query Q@z@a{a@z@a...F@a@z...on T@a@z{b}}`
+	if err != nil || sig != expect {
+		t.Errorf("unexpected signature:\n%s\nexpected:\n%s (%v)", sig, expect, err)
+	}
+
+	// numbers are kept as written when literals are not hidden
+	nums, err := graphql.Parse(`{ price(amount: 12345678.123456789012345, exact: 9007199254740993.0, id: 12345678901234567890) }`)
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+	res, err = graphql.Normalize(nums, "", nil)
+	expect = `{price(amount:12345678.123456789012345,exact:9007199254740993.0,id:12345678901234567890)}`
+	if err != nil || res != expect {
+		t.Errorf("unexpected normalized numbers: %s (%v)", res, err)
+	}
+
+	if _, err := graphql.OperationSignatureHash(doc, ""); err == nil {
+		t.Errorf("expected an error for an ambiguous operation")
+	}
+	h, err := graphql.OperationSignatureHash(doc, "Other")
+	if err != nil || len(h) != 64 {
+		t.Errorf("unexpected hash %q (%v)", h, err)
+	}
+}
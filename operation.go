@@ -49,14 +49,7 @@ type Operation struct {
 }
 
 func (op *Operation) String() string {
-	vals := []string{
-		op.OperationType.String(),
-		op.Name,
-		op.VariableDefinitions.String(),
-		op.Directives.String(),
-		op.SelectionSet.String(),
-	}
-	return strings.Join(vals, " ")
+	return PrintCompact(op)
 }
 
 func (p *Parser) parseOperation() error {
@@ -136,6 +136,7 @@ func (p *Parser) skipSpaces() error {
 					break
 				}
 			}
+			continue
 		}
 		// not a comment, not a space, so we found something
 		return nil
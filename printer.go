@@ -0,0 +1,379 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+type printMode int
+
+const (
+	printPretty printMode = iota
+	printCompact
+	printMinify
+	printReduced // like printMinify, but keeps commas
+)
+
+// Print returns node as a GraphQL document, formatted over multiple
+// indented lines. node can be any element of a parsed document, and
+// parsing the result gives back a structure equal to node.
+//
+// Maps (operations, fragments, arguments, object fields) are printed in
+// name order.
+func Print(node any) string {
+	pr := &printer{mode: printPretty}
+	pr.print(node)
+	return pr.buf.String()
+}
+
+// PrintCompact is like Print but returns node on a single line.
+func PrintCompact(node any) string {
+	pr := &printer{mode: printCompact}
+	pr.print(node)
+	return pr.buf.String()
+}
+
+// PrintMinified is like Print but returns the shortest form of node: no
+// commas, and a single space between tokens only where they would
+// otherwise read as one.
+func PrintMinified(node any) string {
+	pr := &printer{mode: printMinify}
+	pr.print(node)
+	return pr.buf.String()
+}
+
+// Minify parses the document src and returns it minified, without its
+// comments and insignificant whitespace and commas.
+func Minify(src string) (string, error) {
+	doc, err := Parse(src)
+	if err != nil {
+		return "", err
+	}
+	return PrintMinified(doc), nil
+}
+
+type printer struct {
+	buf   strings.Builder
+	mode  printMode
+	depth int
+
+	// minify state
+	pending  bool // a separator is needed before the next token
+	last     byte // last byte written
+	emptyStr bool // last token written was ""
+}
+
+// write appends a token (or part of one) to the output
+func (pr *printer) write(s string) {
+	if s == "" {
+		return
+	}
+	if pr.pending {
+		pr.pending = false
+		if (isWordByte(pr.last) && isWordByte(s[0])) || (pr.emptyStr && s[0] == '"') {
+			// `a b` would become `ab`, `"" "a"` would become a block string
+			pr.buf.WriteByte(' ')
+		}
+	}
+	pr.buf.WriteString(s)
+	pr.last = s[len(s)-1]
+	pr.emptyStr = s == `""`
+}
+
+// sep separates two tokens
+func (pr *printer) sep() {
+	if pr.mode == printMinify || pr.mode == printReduced {
+		pr.pending = true
+		return
+	}
+	pr.write(" ")
+}
+
+// comma separates two items of a list
+func (pr *printer) comma() {
+	switch pr.mode {
+	case printMinify:
+		pr.pending = true
+	case printReduced:
+		pr.write(",")
+	default:
+		pr.write(", ")
+	}
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func (pr *printer) print(node any) {
+	switch n := node.(type) {
+	case *Document:
+		pr.document(n)
+	case *Operation:
+		pr.operation(n)
+	case *Fragment:
+		pr.write("fragment")
+		pr.sep()
+		pr.write(n.Name)
+		pr.sep()
+		pr.typeCondition(n.TypeCondition)
+		pr.directives(n.Directives)
+		pr.sep()
+		pr.selectionSet(n.SelectionSet)
+	case VariableDefinitions:
+		pr.variableDefinitions(n)
+	case *VariableDefinition:
+		pr.variableDefinition(n)
+	case SelectionSet:
+		pr.selectionSet(n)
+	case *Field:
+		pr.field(n)
+	case *InlineFragment:
+		pr.inlineFragment(n)
+	case *FragmentSpread:
+		pr.write("..." + n.Name)
+		pr.directives(n.Directives)
+	case *TypeCondition:
+		pr.typeCondition(n)
+	case Directives:
+		pr.directives(n)
+	case *Directive:
+		pr.directive(n)
+	case Arguments:
+		pr.arguments(n)
+	case *Argument:
+		pr.write(n.Name + ":")
+		pr.sep()
+		pr.value(n.Value)
+	case Value:
+		pr.value(n)
+	default:
+		panic(fmt.Sprintf("cannot print node of type %T", node))
+	}
+}
+
+func (pr *printer) document(d *Document) {
+	first := true
+	next := func() {
+		if first {
+			first = false
+			return
+		}
+		if pr.mode == printPretty {
+			pr.write("\n\n")
+			return
+		}
+		pr.sep()
+	}
+
+	for _, k := range sortedKeys(d.Operations) {
+		next()
+		pr.operation(d.Operations[k])
+	}
+	for _, k := range sortedKeys(d.Fragments) {
+		next()
+		pr.print(d.Fragments[k])
+	}
+}
+
+func (pr *printer) operation(op *Operation) {
+	if op.OperationType == Query && op.Name == "" && len(op.VariableDefinitions) == 0 && len(op.Directives) == 0 {
+		// query shorthand
+		pr.selectionSet(op.SelectionSet)
+		return
+	}
+	pr.write(op.OperationType.String())
+	if op.Name != "" {
+		pr.sep()
+		pr.write(op.Name)
+	}
+	if len(op.VariableDefinitions) > 0 {
+		if op.Name == "" {
+			pr.sep()
+		}
+		pr.variableDefinitions(op.VariableDefinitions)
+	}
+	pr.directives(op.Directives)
+	pr.sep()
+	pr.selectionSet(op.SelectionSet)
+}
+
+func (pr *printer) variableDefinitions(defs VariableDefinitions) {
+	if defs == nil {
+		return
+	}
+	pr.write("(")
+	for i, d := range defs {
+		if i > 0 {
+			pr.comma()
+		}
+		pr.variableDefinition(d)
+	}
+	pr.write(")")
+}
+
+func (pr *printer) variableDefinition(d *VariableDefinition) {
+	pr.write("$" + d.Variable + ":")
+	pr.sep()
+	pr.write(d.Type)
+	if d.DefaultValue != nil {
+		pr.sep()
+		pr.write("=")
+		pr.sep()
+		pr.value(d.DefaultValue)
+	}
+}
+
+func (pr *printer) selectionSet(s SelectionSet) {
+	pr.write("{")
+	pr.depth += 1
+	for i, sel := range s {
+		switch {
+		case pr.mode == printPretty:
+			pr.newline()
+		case i > 0:
+			pr.sep()
+		}
+		pr.print(sel)
+	}
+	pr.depth -= 1
+	if pr.mode == printPretty && len(s) > 0 {
+		pr.newline()
+	}
+	pr.write("}")
+}
+
+func (pr *printer) newline() {
+	pr.write("\n" + strings.Repeat("  ", pr.depth))
+}
+
+func (pr *printer) field(f *Field) {
+	if f.Alias != "" {
+		pr.write(f.Alias + ":")
+		pr.sep()
+	}
+	pr.write(f.Name)
+	pr.arguments(f.Arguments)
+	pr.directives(f.Directives)
+	if len(f.SelectionSet) > 0 {
+		pr.sep()
+		pr.selectionSet(f.SelectionSet)
+	}
+}
+
+func (pr *printer) inlineFragment(f *InlineFragment) {
+	pr.write("...")
+	if f.TypeCondition != nil {
+		pr.sep()
+		pr.typeCondition(f.TypeCondition)
+	}
+	pr.directives(f.Directives)
+	pr.sep()
+	pr.selectionSet(f.SelectionSet)
+}
+
+func (pr *printer) typeCondition(t *TypeCondition) {
+	if t != nil {
+		pr.write("on")
+		pr.sep()
+		pr.write(t.NamedType)
+	}
+}
+
+// directives prints each directive preceded by a separator
+func (pr *printer) directives(ds Directives) {
+	for _, d := range ds {
+		pr.sep()
+		pr.directive(d)
+	}
+}
+
+func (pr *printer) directive(d *Directive) {
+	pr.write("@" + d.Directive)
+	pr.arguments(d.Arguments)
+}
+
+func (pr *printer) arguments(args Arguments) {
+	if len(args) == 0 {
+		return
+	}
+	pr.write("(")
+	for i, k := range sortedKeys(args) {
+		if i > 0 {
+			pr.comma()
+		}
+		pr.write(k + ":")
+		pr.sep()
+		pr.value(args[k])
+	}
+	pr.write(")")
+}
+
+func (pr *printer) value(v Value) {
+	switch n := v.(type) {
+	case StringValue:
+		pr.stringValue(string(n))
+	case ListValue:
+		pr.write("[")
+		for i, sub := range n {
+			if i > 0 {
+				pr.comma()
+			}
+			pr.value(sub)
+		}
+		pr.write("]")
+	case ObjectValue:
+		pr.write("{")
+		for i, k := range sortedKeys(n) {
+			if i > 0 {
+				pr.comma()
+			}
+			pr.write(k + ":")
+			pr.sep()
+			pr.value(n[k])
+		}
+		pr.write("}")
+	default:
+		// VariableValue, IntValue, FloatValue, BooleanValue, EnumValue, NullValue
+		pr.write(v.String())
+	}
+}
+
+// stringValue prints s as a quoted StringValue
+func (pr *printer) stringValue(s string) {
+	b := &strings.Builder{}
+	b.WriteByte('"')
+	for i, c := range s {
+		switch c {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(c)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case utf8.RuneError:
+			// keep invalid bytes as they are
+			if _, ln := utf8.DecodeRuneInString(s[i:]); ln == 1 {
+				b.WriteByte(s[i])
+				continue
+			}
+			b.WriteRune(c)
+		default:
+			if c < 0x20 || c == 0x7f {
+				fmt.Fprintf(b, `\u%04x`, c)
+				continue
+			}
+			b.WriteRune(c)
+		}
+	}
+	b.WriteByte('"')
+	pr.write(b.String())
+}
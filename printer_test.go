@@ -0,0 +1,293 @@
+package graphql_test
+
+import (
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/KarpelesLab/graphql"
+)
+
+func TestPrint(t *testing.T) {
+	src := `query Q($id: ID!, $n: [Int!] = [1, 2]) @live {
+  big(exact: 9007199254740993.0, huge: 1e400, id: 12345678901234567890, precise: 12345678.123456789012345)
+  hero: character(filter: {height: -1500.5, name: "R2\"D2\n", tags: []}, id: $id) {
+    name
+    ... on Droid @include(if: true) {
+      primaryFunction
+    }
+    ... {
+      id
+    }
+    ...F @skip(if: $skip)
+  }
+}
+
+fragment F on Character @deprecated {
+  friends(after: null, first: 10, order: ASC) {
+    name
+  }
+}`
+	doc, err := graphql.Parse(src)
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+	if res := graphql.Print(doc); res != src {
+		t.Errorf("unexpected print result:\n%s", res)
+	}
+	if res := doc.Operations["Q"].SelectionSet[1].String(); !strings.HasPrefix(res, `hero: character(filter: {height: -1500.5, name: "R2\"D2\n", tags: []}, id: $id) {name ... on Droid @include(if: true) {primaryFunction} ... {id} ...F @skip(if: $skip)}`) {
+		t.Errorf("unexpected compact print result: %s", res)
+	}
+
+	// VariableDefinitions and Arguments must have at least one entry
+	if _, err := graphql.Parse(`query Q () { a }`); err == nil {
+		t.Errorf("expected empty variable definitions to be rejected")
+	}
+	if _, err := graphql.Parse(`{ f() }`); err == nil {
+		t.Errorf("expected empty arguments to be rejected")
+	}
+	if _, err := graphql.Parse(`{ f @include() }`); err == nil {
+		t.Errorf("expected empty directive arguments to be rejected")
+	}
+	op := &graphql.Operation{VariableDefinitions: graphql.VariableDefinitions{}, SelectionSet: graphql.SelectionSet{&graphql.Field{Name: "a"}}}
+	if res := graphql.PrintCompact(op); res != `{a}` {
+		t.Errorf("unexpected print of empty variable definitions: %s", res)
+	}
+}
+
+func TestMinify(t *testing.T) {
+	src := `# a comment
+query Q($id: ID!, $n: [Int!] = [1, -2, 3.5e1]) {
+  hero(id: $id, names: ["", "a", ""], e: [A, B]) { # inline comment
+    name , id
+    ... on Droid @include(if: true) { primaryFunction }
+    ...F
+  }
+}
+fragment F on Character { id }`
+	res, err := graphql.Minify(src)
+	if err != nil {
+		t.Fatalf("minify failed: %s", err)
+	}
+	expect := `query Q($id:ID!$n:[Int!]=[1-2 3.5e1]){hero(e:[A B]id:$id names:["" "a"""]){name id...on Droid@include(if:true){primaryFunction}...F}}fragment F on Character{id}`
+	if res != expect {
+		t.Errorf("unexpected minified document:\n%s\nexpected:\n%s", res, expect)
+	}
+	if again, err := graphql.Minify(res); err != nil || again != res {
+		t.Errorf("minified document is not stable: %s (%v)", again, err)
+	}
+
+	// numbers are kept byte for byte, whatever their size or precision
+	res, err = graphql.Minify(`{ price(amount: 12345678.123456789012345, exact: 9007199254740993.0, id: 12345678901234567890, big: -1E+400) }`)
+	expect = `{price(amount:12345678.123456789012345 big:-1E+400 exact:9007199254740993.0 id:12345678901234567890)}`
+	if err != nil || res != expect {
+		t.Errorf("unexpected minified numbers: %s (%v)", res, err)
+	}
+}
+
+// TestPrintRoundTrip checks on random documents that parsing the printed
+// form of a document gives back the same document.
+func TestPrintRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 500; i++ {
+		doc := randDocument(r)
+		for _, s := range []string{graphql.Print(doc), graphql.PrintCompact(doc), graphql.PrintMinified(doc)} {
+			res, err := graphql.Parse(s)
+			if err != nil {
+				t.Fatalf("failed to parse printed document: %s\n%s", err, s)
+			}
+			if !reflect.DeepEqual(res, doc) {
+				t.Fatalf("document changed after round trip:\n%s\n%s", s, graphql.Print(res))
+			}
+		}
+	}
+}
+
+func randName(r *rand.Rand) string {
+	const first = "_abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	const rest = first + "0123456789"
+
+	for {
+		b := []byte{first[r.Intn(len(first))]}
+		for n := r.Intn(6); n > 0; n-- {
+			b = append(b, rest[r.Intn(len(rest))])
+		}
+		switch s := string(b); s {
+		case "on", "true", "false", "null":
+			// reserved in some positions
+		default:
+			return s
+		}
+	}
+}
+
+func randDocument(r *rand.Rand) *graphql.Document {
+	doc := &graphql.Document{
+		Operations: make(map[string]*graphql.Operation),
+		Fragments:  make(map[string]*graphql.Fragment),
+	}
+	for n := 1 + r.Intn(3); n > 0; n-- {
+		op := &graphql.Operation{
+			OperationType: graphql.OperationType(r.Intn(3)),
+			Directives:    randDirectives(r),
+			SelectionSet:  randSelectionSet(r, 3),
+		}
+		if r.Intn(4) > 0 {
+			op.Name = randName(r)
+		}
+		if r.Intn(2) == 0 {
+			for n := 1 + r.Intn(3); n > 0; n-- {
+				def := &graphql.VariableDefinition{Variable: randName(r), Type: randType(r)}
+				if r.Intn(2) == 0 {
+					def.DefaultValue = randValue(r, 2)
+				}
+				op.VariableDefinitions = append(op.VariableDefinitions, def)
+			}
+		}
+		doc.Operations[op.Name] = op
+	}
+	for n := r.Intn(3); n > 0; n-- {
+		f := &graphql.Fragment{
+			Name:          randName(r),
+			TypeCondition: &graphql.TypeCondition{NamedType: randName(r)},
+			Directives:    randDirectives(r),
+			SelectionSet:  randSelectionSet(r, 3),
+		}
+		doc.Fragments[f.Name] = f
+	}
+	return doc
+}
+
+func randType(r *rand.Rand) string {
+	res := randName(r)
+	if r.Intn(3) == 0 {
+		res = "[" + randType(r) + "]"
+	}
+	if r.Intn(2) == 0 {
+		res += "!"
+	}
+	return res
+}
+
+func randSelectionSet(r *rand.Rand, depth int) graphql.SelectionSet {
+	var res graphql.SelectionSet
+
+	for n := 1 + r.Intn(4); n > 0; n-- {
+		switch k := r.Intn(6); {
+		case k == 0 && depth > 0:
+			f := &graphql.InlineFragment{Directives: randDirectives(r), SelectionSet: randSelectionSet(r, depth-1)}
+			if r.Intn(2) == 0 {
+				f.TypeCondition = &graphql.TypeCondition{NamedType: randName(r)}
+			}
+			res = append(res, f)
+		case k == 1:
+			res = append(res, &graphql.FragmentSpread{Name: randName(r), Directives: randDirectives(r)})
+		default:
+			f := &graphql.Field{Name: randName(r), Arguments: randArguments(r), Directives: randDirectives(r)}
+			if r.Intn(3) == 0 {
+				f.Alias = randName(r)
+			}
+			if depth > 0 && r.Intn(3) == 0 {
+				f.SelectionSet = randSelectionSet(r, depth-1)
+			}
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+func randDirectives(r *rand.Rand) graphql.Directives {
+	var res graphql.Directives
+	for n := r.Intn(3) - 1; n > 0; n-- {
+		res = append(res, &graphql.Directive{Directive: randName(r), Arguments: randArguments(r)})
+	}
+	return res
+}
+
+func randArguments(r *rand.Rand) graphql.Arguments {
+	if r.Intn(2) == 0 {
+		return nil
+	}
+	res := make(graphql.Arguments)
+	for n := 1 + r.Intn(3); n > 0; n-- {
+		res[randName(r)] = randValue(r, 2)
+	}
+	return res
+}
+
+// randInt returns an IntValue token, possibly too large for any Go integer
+func randInt(r *rand.Rand) string {
+	var b []byte
+	if r.Intn(2) == 0 {
+		b = append(b, '-')
+	}
+	if r.Intn(4) == 0 {
+		return string(append(b, '0'))
+	}
+	b = append(b, byte('1'+r.Intn(9)))
+	for n := r.Intn(30); n > 0; n-- {
+		b = append(b, byte('0'+r.Intn(10)))
+	}
+	return string(b)
+}
+
+// randFloat returns a FloatValue token, with digits or exponents beyond what
+// a float64 holds
+func randFloat(r *rand.Rand) string {
+	res := randInt(r)
+	hasFrac := r.Intn(3) > 0
+	if hasFrac {
+		res += "."
+		for n := 1 + r.Intn(25); n > 0; n-- {
+			res += string(rune('0' + r.Intn(10)))
+		}
+	}
+	if !hasFrac || r.Intn(2) == 0 {
+		res += []string{"e", "E"}[r.Intn(2)] + []string{"", "+", "-"}[r.Intn(3)] + strconv.Itoa(r.Intn(1000))
+	}
+	return res
+}
+
+func randValue(r *rand.Rand, depth int) graphql.Value {
+	switch r.Intn(10) {
+	case 0:
+		return &graphql.VariableValue{Var: randName(r)}
+	case 1:
+		return graphql.IntValue(randInt(r))
+	case 2:
+		return graphql.FloatValue(randFloat(r))
+	case 3:
+		return graphql.BooleanValue(r.Intn(2) == 0)
+	case 4:
+		return graphql.NullValue{}
+	case 5:
+		return graphql.EnumValue(randName(r))
+	case 6:
+		if depth > 0 {
+			res := graphql.ListValue{}
+			for n := r.Intn(3); n > 0; n-- {
+				res = append(res, randValue(r, depth-1))
+			}
+			return res
+		}
+	case 7:
+		if depth > 0 {
+			res := make(graphql.ObjectValue)
+			for n := r.Intn(3); n > 0; n-- {
+				res[randName(r)] = randValue(r, depth-1)
+			}
+			return res
+		}
+	}
+	// string with some characters needing escapes
+	const chars = "ab c\"\\\n\r\t\b\f\x00\x1f/é€😀"
+	runes := []rune(chars)
+	var b strings.Builder
+	for n := r.Intn(8); n > 0; n-- {
+		b.WriteRune(runes[r.Intn(len(runes))])
+	}
+	return graphql.StringValue(b.String())
+}
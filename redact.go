@@ -0,0 +1,235 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Redactor replaces sensitive literal values of a document with the same
+// placeholders as Normalize: 0, "", [] and {}. Variables, enum values,
+// booleans and null are left as they are.
+type Redactor struct {
+	All bool // redact every literal
+
+	// Arguments lists argument names whose values are always redacted, for
+	// example "password". They also match fields of input objects at any
+	// depth, as in login(input: {password: "..."}).
+	Arguments []string
+
+	// KeepDefaultValues leaves the default values of variable definitions
+	// as they are. Otherwise, the default value of a variable is redacted
+	// when the variable is used where values are redacted, or always with
+	// All. Variables are matched by name across the whole document.
+	KeepDefaultValues bool
+
+	// Coordinates lists schema coordinates to redact: "Type.field(arg:)" for
+	// a single argument, "Type.field" for all arguments of a field, or
+	// "@directive(arg:)" for a directive argument.
+	//
+	// Without a schema, the type of a field is only known from the type
+	// condition of its enclosing fragment or from the operation type (Query,
+	// Mutation or Subscription) at the root. When it is unknown, coordinates
+	// match on field and argument names alone, so values are redacted
+	// rather than leaked.
+	Coordinates []string
+}
+
+// Redact returns a copy of doc with sensitive literals replaced.
+func (r *Redactor) Redact(doc *Document) (*Document, error) {
+	coords := make([]redactCoordinate, 0, len(r.Coordinates))
+	for _, s := range r.Coordinates {
+		c, err := parseRedactCoordinate(s)
+		if err != nil {
+			return nil, err
+		}
+		coords = append(coords, c)
+	}
+	args := make(map[string]bool)
+	for _, a := range r.Arguments {
+		args[a] = true
+	}
+
+	var sensitive map[string]bool
+	if !r.All && !r.KeepDefaultValues {
+		sensitive = sensitiveVariables(doc, args, coords)
+	}
+
+	rw := &Rewriter{
+		VariableDefinition: func(c *Cursor, d *VariableDefinition) *VariableDefinition {
+			if r.KeepDefaultValues || d.DefaultValue == nil || (!r.All && !sensitive[d.Variable]) {
+				return d
+			}
+			n := *d
+			n.DefaultValue = hideLiteral(c, d.DefaultValue)
+			return &n
+		},
+		Value: func(c *Cursor, v Value) Value {
+			if r.All {
+				if r.KeepDefaultValues && inDefaultValue(c) {
+					return v
+				}
+				return hideLiteral(c, v)
+			}
+			if redactedObjectField(c, args) {
+				return hideLiteral(c, v)
+			}
+			return v
+		},
+	}
+	if !r.All {
+		rw.Argument = func(c *Cursor, a *Argument) *Argument {
+			if !args[a.Name] && !matchRedactCoordinates(coords, c, a) {
+				return a
+			}
+			return &Argument{Name: a.Name, Value: hideLiteral(c, a.Value)}
+		}
+	}
+	return Rewrite(doc, rw), nil
+}
+
+// redactedObjectField returns true if c is on the value of an input object
+// field named in args
+func redactedObjectField(c *Cursor, args map[string]bool) bool {
+	if _, ok := c.Parent().(ObjectValue); !ok {
+		return false
+	}
+	path := c.Path()
+	k, ok := path[len(path)-1].(string)
+	return ok && args[k]
+}
+
+// sensitiveVariables returns the names of the variables used in doc where
+// values are redacted
+func sensitiveVariables(doc *Document, args map[string]bool, coords []redactCoordinate) map[string]bool {
+	res := make(map[string]bool)
+	collect := func(v Value) {
+		Walk(&Visitor{
+			EnterValue: func(c *Cursor, v Value) WalkAction {
+				if vv, ok := v.(*VariableValue); ok {
+					res[vv.Var] = true
+				}
+				return WalkContinue
+			},
+		}, v)
+	}
+
+	Walk(&Visitor{
+		EnterArgument: func(c *Cursor, a *Argument) WalkAction {
+			if args[a.Name] || matchRedactCoordinates(coords, c, a) {
+				collect(a.Value)
+				return WalkSkip
+			}
+			return WalkContinue
+		},
+		EnterValue: func(c *Cursor, v Value) WalkAction {
+			if redactedObjectField(c, args) {
+				collect(v)
+				return WalkSkip
+			}
+			return WalkContinue
+		},
+	}, doc)
+	return res
+}
+
+// inDefaultValue returns true if c is within the default value of a
+// variable definition
+func inDefaultValue(c *Cursor) bool {
+	for _, n := range c.Ancestors() {
+		if _, ok := n.(*VariableDefinition); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Print returns doc on a single line with sensitive literals replaced, ready
+// to be logged.
+func (r *Redactor) Print(doc *Document) (string, error) {
+	res, err := r.Redact(doc)
+	if err != nil {
+		return "", err
+	}
+	return PrintCompact(res), nil
+}
+
+type redactCoordinate struct {
+	typ, field, directive, arg string
+}
+
+func parseRedactCoordinate(s string) (redactCoordinate, error) {
+	var res redactCoordinate
+	name := s
+
+	if i := strings.IndexByte(name, '('); i >= 0 {
+		if !strings.HasSuffix(name, ":)") {
+			return res, fmt.Errorf("invalid schema coordinate %s", s)
+		}
+		res.arg = name[i+1 : len(name)-2]
+		name = name[:i]
+	}
+	if strings.HasPrefix(name, "@") {
+		res.directive = name[1:]
+		if res.arg == "" || res.directive == "" {
+			return res, fmt.Errorf("invalid schema coordinate %s", s)
+		}
+		return res, nil
+	}
+	typ, field, ok := strings.Cut(name, ".")
+	if !ok || typ == "" || field == "" {
+		return res, fmt.Errorf("invalid schema coordinate %s", s)
+	}
+	res.typ, res.field = typ, field
+	return res, nil
+}
+
+func matchRedactCoordinates(coords []redactCoordinate, c *Cursor, a *Argument) bool {
+	if len(coords) == 0 {
+		return false
+	}
+
+	switch n := c.Parent().(type) {
+	case *Directive:
+		for _, co := range coords {
+			if co.directive == n.Directive && co.arg == a.Name {
+				return true
+			}
+		}
+	case *Field:
+		typ := parentTypeName(c.Ancestors())
+		for _, co := range coords {
+			if co.field == n.Name && (co.arg == "" || co.arg == a.Name) && (typ == "" || co.typ == typ) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parentTypeName returns the type of the object holding the field that is
+// the last of ancestors, or an empty string if unknown
+func parentTypeName(ancestors []any) string {
+	// skip the field itself
+	for i := len(ancestors) - 2; i >= 0; i-- {
+		switch n := ancestors[i].(type) {
+		case *Field:
+			return ""
+		case *InlineFragment:
+			if n.TypeCondition != nil {
+				return n.TypeCondition.NamedType
+			}
+		case *Fragment:
+			return n.TypeCondition.NamedType
+		case *Operation:
+			switch n.OperationType {
+			case Mutation:
+				return "Mutation"
+			case Subscription:
+				return "Subscription"
+			default:
+				return "Query"
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+package graphql_test
+
+import (
+	"testing"
+
+	"github.com/KarpelesLab/graphql"
+)
+
+func TestRedactor(t *testing.T) {
+	doc, err := graphql.Parse(`mutation {
+  login(email: "a@example.com", password: "hunter2", remember: true) { token }
+  user(id: 42) {
+    ... on User { update(email: "b@example.com", name: "Bob") { id } }
+    friends(token: "secret", first: 10) { name }
+  }
+  other(email: "c@example.com") @auth(token: "t0k") { id }
+}`)
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+
+	r := &graphql.Redactor{
+		Arguments:   []string{"password"},
+		Coordinates: []string{"Mutation.login(email:)", "User.update", "Friend.friends(token:)", "@auth(token:)"},
+	}
+	res, err := r.Print(doc)
+	if err != nil {
+		t.Fatalf("failed to redact: %s", err)
+	}
+	expect := `mutation {login(email: "", password: "", remember: true) {token} user(id: 42) {... on User {update(email: "", name: "") {id}} friends(first: 10, token: "") {name}} other(email: "c@example.com") @auth(token: "") {id}}`
+	if res != expect {
+		t.Errorf("unexpected redacted document:\n%s\nexpected:\n%s", res, expect)
+	}
+	if _, err := graphql.Parse(res); err != nil {
+		t.Errorf("redacted document does not parse: %s", err)
+	}
+
+	res, err = (&graphql.Redactor{All: true}).Print(doc)
+	if err != nil {
+		t.Fatalf("failed to redact: %s", err)
+	}
+	expect = `mutation {login(email: "", password: "", remember: true) {token} user(id: 0) {... on User {update(email: "", name: "") {id}} friends(first: 0, token: "") {name}} other(email: "") @auth(token: "") {id}}`
+	if res != expect {
+		t.Errorf("unexpected redacted document:\n%s\nexpected:\n%s", res, expect)
+	}
+
+	// nested input object fields and default values
+	doc, err = graphql.Parse(`mutation M($pw: String = "defaultsecret", $old: String = "oldsecret", $tok: String = "t0k", $n: Int = 3) {
+  login(input: {email: "a@b.c", password: "hunter2", nested: [{password: {old: $old}}]}, n: $n) { token }
+  change(password: $pw) @auth(token: $tok) { id }
+}`)
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+	// only defaults of variables used where values are redacted are hidden
+	r = &graphql.Redactor{Arguments: []string{"password"}, Coordinates: []string{"@auth(token:)"}}
+	res, err = r.Print(doc)
+	expect = `mutation M($pw: String = "", $old: String = "", $tok: String = "", $n: Int = 3) {login(input: {email: "a@b.c", nested: [{password: {}}], password: ""}, n: $n) {token} change(password: $pw) @auth(token: $tok) {id}}`
+	if err != nil || res != expect {
+		t.Errorf("unexpected redacted document:\n%s\nexpected:\n%s (%v)", res, expect, err)
+	}
+	r.KeepDefaultValues = true
+	res, err = r.Print(doc)
+	expect = `mutation M($pw: String = "defaultsecret", $old: String = "oldsecret", $tok: String = "t0k", $n: Int = 3) {login(input: {email: "a@b.c", nested: [{password: {}}], password: ""}, n: $n) {token} change(password: $pw) @auth(token: $tok) {id}}`
+	if err != nil || res != expect {
+		t.Errorf("unexpected redacted document:\n%s\nexpected:\n%s (%v)", res, expect, err)
+	}
+	res, err = (&graphql.Redactor{All: true}).Print(doc)
+	expect = `mutation M($pw: String = "", $old: String = "", $tok: String = "", $n: Int = 0) {login(input: {}, n: $n) {token} change(password: $pw) @auth(token: $tok) {id}}`
+	if err != nil || res != expect {
+		t.Errorf("unexpected redacted document:\n%s\nexpected:\n%s (%v)", res, expect, err)
+	}
+	res, err = (&graphql.Redactor{All: true, KeepDefaultValues: true}).Print(doc)
+	expect = `mutation M($pw: String = "defaultsecret", $old: String = "oldsecret", $tok: String = "t0k", $n: Int = 3) {login(input: {}, n: $n) {token} change(password: $pw) @auth(token: $tok) {id}}`
+	if err != nil || res != expect {
+		t.Errorf("unexpected redacted document:\n%s\nexpected:\n%s (%v)", res, expect, err)
+	}
+
+	if _, err := (&graphql.Redactor{Coordinates: []string{"nope"}}).Redact(doc); err == nil {
+		t.Errorf("expected an error for an invalid coordinate")
+	}
+}
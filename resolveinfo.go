@@ -0,0 +1,56 @@
+package graphql
+
+import "strings"
+
+// ResolveInfo describes the field being resolved, and gives access to the
+// selections requested below it once fragments, @skip and @include are
+// applied.
+type ResolveInfo struct {
+	Field       *Field
+	Path        []any  // response path of the field, keys (string) and list indices (int)
+	ParentType  string // type of the object holding the field
+	ReturnType  string // named type returned by the field
+	Variables   map[string]any
+	Fragments   map[string]*Fragment
+	TypeApplies TypeApplies // see CollectFields
+}
+
+// CollectedChildren returns the fields selected below the field being
+// resolved, for an object of type ReturnType.
+func (info *ResolveInfo) CollectedChildren() (CollectedFields, error) {
+	return CollectFields(info.TypeApplies, info.ReturnType, info.Field.SelectionSet, info.Variables, info.Fragments)
+}
+
+// Selected reports whether the field names in path, separated by dots, are
+// selected below the field being resolved, for example "edges.node.author".
+// Only the first level is checked against ReturnType, as the types of
+// deeper fields are not known.
+//
+// Errors from CollectFields, such as a missing @include variable or an
+// unknown fragment, are returned rather than reported as not selected.
+func (info *ResolveInfo) Selected(path string) (bool, error) {
+	typ := info.ReturnType
+	sl := info.Field.SelectionSet
+
+	for _, name := range strings.Split(path, ".") {
+		fields, err := CollectFields(info.TypeApplies, typ, sl, info.Variables, info.Fragments)
+		if err != nil {
+			return false, err
+		}
+		typ = ""
+		sl = nil
+		found := false
+		for _, g := range fields {
+			for _, f := range g.Fields {
+				if f.Name == name {
+					found = true
+					sl = append(sl, f.SelectionSet...)
+				}
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,62 @@
+package graphql_test
+
+import (
+	"testing"
+
+	"github.com/KarpelesLab/graphql"
+)
+
+func TestResolveInfo(t *testing.T) {
+	doc, err := graphql.Parse(`{
+  posts(first: 10) {
+    edges { node { title ...Author } }
+    total @include(if: $withTotal)
+    ... on PostConnection { pageInfo { hasNextPage } }
+    ... on OtherConnection { other }
+  }
+}
+fragment Author on Post { author { name } }`)
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+
+	info := &graphql.ResolveInfo{
+		Field:      doc.Operations[""].SelectionSet[0].(*graphql.Field),
+		Path:       []any{"posts"},
+		ParentType: "Query",
+		ReturnType: "PostConnection",
+		Variables:  map[string]any{"withTotal": false},
+		Fragments:  doc.Fragments,
+	}
+
+	children, err := info.CollectedChildren()
+	if err != nil {
+		t.Fatalf("failed to collect children: %s", err)
+	}
+	if len(children) != 2 || children[0].Key != "edges" || children[1].Key != "pageInfo" {
+		t.Errorf("unexpected children %v", children)
+	}
+
+	for path, expect := range map[string]bool{
+		"edges.node.author":      true,
+		"edges.node.author.name": true,
+		"edges.node.body":        false,
+		"total":                  false,
+		"pageInfo.hasNextPage":   true,
+		"other":                  false,
+	} {
+		if res, err := info.Selected(path); err != nil || res != expect {
+			t.Errorf("Selected(%q) should be %v, got %v (%v)", path, expect, res, err)
+		}
+	}
+
+	info.Variables = nil
+	if _, err := info.Selected("total"); err == nil {
+		t.Errorf("expected an error for a missing @include variable")
+	}
+	info.Variables = map[string]any{"withTotal": true}
+	info.Fragments = nil
+	if _, err := info.Selected("edges.node.author"); err == nil {
+		t.Errorf("expected an error for an unknown fragment")
+	}
+}
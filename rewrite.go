@@ -0,0 +1,355 @@
+package graphql
+
+import "reflect"
+
+// Rewriter holds the callbacks invoked by Rewrite. Each callback receives a
+// node after its children were rewritten and returns its replacement: the
+// node itself to keep it, a new node to replace it, or nil to remove it.
+// Callbacks must not modify the nodes they receive, and any of them may be
+// nil.
+//
+// The Cursor passed to callbacks describes the position of the node in the
+// original document.
+type Rewriter struct {
+	Operation          func(c *Cursor, op *Operation) *Operation
+	Fragment           func(c *Cursor, f *Fragment) *Fragment
+	VariableDefinition func(c *Cursor, v *VariableDefinition) *VariableDefinition
+	SelectionSet       func(c *Cursor, s SelectionSet) SelectionSet
+	Selection          func(c *Cursor, s Selection) Selection
+	Directive          func(c *Cursor, d *Directive) *Directive
+	Argument           func(c *Cursor, a *Argument) *Argument
+	Value              func(c *Cursor, v Value) Value // also called on items of lists and objects
+}
+
+// Rewrite returns a new document with the callbacks of r applied. doc is
+// left untouched, and nodes that were not changed (nor any of their
+// children) are shared between doc and the returned document.
+func Rewrite(doc *Document, r *Rewriter) *Document {
+	rw := &rewriter{r: r}
+	rw.push(doc)
+	defer rw.pop(0)
+
+	res := newDocument()
+	for _, k := range sortedKeys(doc.Operations) {
+		l := rw.push(doc.Operations[k], "operations", k)
+		if op := rw.operation(doc.Operations[k]); op != nil {
+			res.Operations[op.Name] = op
+		}
+		rw.pop(l)
+	}
+	for _, k := range sortedKeys(doc.Fragments) {
+		l := rw.push(doc.Fragments[k], "fragments", k)
+		if f := rw.fragment(doc.Fragments[k]); f != nil {
+			res.Fragments[f.Name] = f
+		}
+		rw.pop(l)
+	}
+	return res
+}
+
+type rewriter struct {
+	r *Rewriter
+	c Cursor
+}
+
+// push adds node to the cursor and returns the previous path length, to
+// be passed to pop
+func (rw *rewriter) push(node any, keys ...any) int {
+	l := len(rw.c.path)
+	rw.c.nodes = append(rw.c.nodes, node)
+	rw.c.path = append(rw.c.path, keys...)
+	return l
+}
+
+func (rw *rewriter) pop(l int) {
+	rw.c.nodes = rw.c.nodes[:len(rw.c.nodes)-1]
+	rw.c.path = rw.c.path[:l]
+}
+
+func (rw *rewriter) operation(op *Operation) *Operation {
+	defs, ch1 := rw.variableDefinitions(op.VariableDefinitions)
+	dirs, ch2 := rw.directives(op.Directives)
+	sl, ch3 := rw.selectionSet(op.SelectionSet)
+	if ch1 || ch2 || ch3 {
+		n := *op
+		n.VariableDefinitions, n.Directives, n.SelectionSet = defs, dirs, sl
+		op = &n
+	}
+	if rw.r.Operation != nil {
+		return rw.r.Operation(&rw.c, op)
+	}
+	return op
+}
+
+func (rw *rewriter) fragment(f *Fragment) *Fragment {
+	dirs, ch1 := rw.directives(f.Directives)
+	sl, ch2 := rw.selectionSet(f.SelectionSet)
+	if ch1 || ch2 {
+		n := *f
+		n.Directives, n.SelectionSet = dirs, sl
+		f = &n
+	}
+	if rw.r.Fragment != nil {
+		return rw.r.Fragment(&rw.c, f)
+	}
+	return f
+}
+
+func (rw *rewriter) variableDefinitions(defs VariableDefinitions) (VariableDefinitions, bool) {
+	var res VariableDefinitions
+	changed := false
+
+	for i, d := range defs {
+		l := rw.push(d, "variable_definitions", i)
+		nd := d
+		if d.DefaultValue != nil {
+			l2 := rw.push(d.DefaultValue, "default_value")
+			if v, ch := rw.value(d.DefaultValue); ch {
+				n := *d
+				n.DefaultValue = v
+				nd = &n
+			}
+			rw.pop(l2)
+		}
+		if rw.r.VariableDefinition != nil {
+			nd = rw.r.VariableDefinition(&rw.c, nd)
+		}
+		rw.pop(l)
+
+		if nd != d {
+			changed = true
+		}
+		if nd != nil {
+			res = append(res, nd)
+		}
+	}
+	if !changed {
+		return defs, false
+	}
+	return res, true
+}
+
+func (rw *rewriter) selectionSet(s SelectionSet) (SelectionSet, bool) {
+	if s == nil {
+		return nil, false
+	}
+	l := rw.push(s, "selection_set")
+	defer rw.pop(l)
+
+	var res SelectionSet
+	changed := false
+
+	for i, sel := range s {
+		l := rw.push(sel, i)
+		nsel := rw.selection(sel)
+		rw.pop(l)
+
+		if !sameNode(nsel, sel) {
+			changed = true
+		}
+		if nsel != nil {
+			res = append(res, nsel)
+		}
+	}
+	if !changed {
+		res = s
+	} else if res == nil {
+		res = SelectionSet{}
+	}
+
+	if rw.r.SelectionSet != nil {
+		n := rw.r.SelectionSet(&rw.c, res)
+		if !sameNode(n, res) {
+			res, changed = n, true
+		}
+	}
+	return res, changed
+}
+
+func (rw *rewriter) selection(sel Selection) Selection {
+	switch s := sel.(type) {
+	case *Field:
+		args, ch1 := rw.arguments(s.Arguments)
+		dirs, ch2 := rw.directives(s.Directives)
+		sl, ch3 := rw.selectionSet(s.SelectionSet)
+		if ch1 || ch2 || ch3 {
+			n := *s
+			n.Arguments, n.Directives, n.SelectionSet = args, dirs, sl
+			sel = &n
+		}
+	case *InlineFragment:
+		dirs, ch1 := rw.directives(s.Directives)
+		sl, ch2 := rw.selectionSet(s.SelectionSet)
+		if ch1 || ch2 {
+			n := *s
+			n.Directives, n.SelectionSet = dirs, sl
+			sel = &n
+		}
+	case *FragmentSpread:
+		if dirs, ch := rw.directives(s.Directives); ch {
+			n := *s
+			n.Directives = dirs
+			sel = &n
+		}
+	}
+	if rw.r.Selection != nil {
+		return rw.r.Selection(&rw.c, sel)
+	}
+	return sel
+}
+
+func (rw *rewriter) directives(ds Directives) (Directives, bool) {
+	var res Directives
+	changed := false
+
+	for i, d := range ds {
+		l := rw.push(d, "directives", i)
+		nd := d
+		if args, ch := rw.arguments(d.Arguments); ch {
+			nd = &Directive{Directive: d.Directive, Arguments: args}
+		}
+		if rw.r.Directive != nil {
+			nd = rw.r.Directive(&rw.c, nd)
+		}
+		rw.pop(l)
+
+		if nd != d {
+			changed = true
+		}
+		if nd != nil {
+			res = append(res, nd)
+		}
+	}
+	if !changed {
+		return ds, false
+	}
+	return res, true
+}
+
+func (rw *rewriter) arguments(args Arguments) (Arguments, bool) {
+	if args == nil {
+		return nil, false
+	}
+	res := make(Arguments)
+	changed := false
+
+	for _, k := range sortedKeys(args) {
+		a := &Argument{Name: k, Value: args[k]}
+		l := rw.push(a, "arguments", k)
+		na := a
+		l2 := rw.push(a.Value, "value")
+		if v, ch := rw.value(a.Value); ch {
+			na = &Argument{Name: k, Value: v}
+			if v == nil {
+				// value was removed, remove the argument too
+				na = nil
+			}
+		}
+		rw.pop(l2)
+		if rw.r.Argument != nil && na != nil {
+			na = rw.r.Argument(&rw.c, na)
+		}
+		rw.pop(l)
+
+		if na == nil || na.Name != k || !sameNode(na.Value, a.Value) {
+			changed = true
+		}
+		if na != nil {
+			res[na.Name] = na.Value
+		}
+	}
+	if !changed {
+		return args, false
+	}
+	return res, true
+}
+
+// value rewrites v, which must already have been pushed to the cursor
+func (rw *rewriter) value(v Value) (Value, bool) {
+	changed := false
+
+	switch n := v.(type) {
+	case ListValue:
+		res := make(ListValue, 0, len(n))
+		for i, sub := range n {
+			l := rw.push(sub, i)
+			nsub, ch := rw.value(sub)
+			rw.pop(l)
+			if ch {
+				changed = true
+			}
+			if nsub != nil {
+				res = append(res, nsub)
+			}
+		}
+		if changed {
+			v = res
+		}
+	case ObjectValue:
+		res := make(ObjectValue)
+		for _, k := range sortedKeys(n) {
+			l := rw.push(n[k], k)
+			nsub, ch := rw.value(n[k])
+			rw.pop(l)
+			if ch {
+				changed = true
+			}
+			if nsub != nil {
+				res[k] = nsub
+			}
+		}
+		if changed {
+			v = res
+		}
+	}
+
+	if rw.r.Value != nil {
+		n := rw.r.Value(&rw.c, v)
+		if !sameNode(n, v) {
+			v, changed = n, true
+		}
+	}
+	return v, changed
+}
+
+// sameNode returns true if a and b are the same node. Unlike ==, it does not
+// panic on nodes that are slices or maps, which are the same if they share
+// the same backing storage.
+func sameNode(a, b any) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Type() != vb.Type() {
+		return false
+	}
+	switch va.Kind() {
+	case reflect.Slice:
+		return va.Pointer() == vb.Pointer() && va.Len() == vb.Len()
+	case reflect.Map, reflect.Pointer:
+		return va.Pointer() == vb.Pointer()
+	default:
+		return va.Interface() == vb.Interface()
+	}
+}
+
+// AddTypename returns a copy of doc with a __typename field selected in
+// every selection set except the root of operations, as needed by clients
+// that cache results by type.
+func AddTypename(doc *Document) *Document {
+	return Rewrite(doc, &Rewriter{
+		SelectionSet: func(c *Cursor, s SelectionSet) SelectionSet {
+			if _, ok := c.Parent().(*Operation); ok {
+				return s
+			}
+			for _, sel := range s {
+				if f, ok := sel.(*Field); ok && f.Name == "__typename" && f.Alias == "" {
+					return s
+				}
+			}
+			res := make(SelectionSet, 0, len(s)+1)
+			res = append(res, s...)
+			return append(res, &Field{Name: "__typename"})
+		},
+	})
+}
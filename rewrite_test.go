@@ -0,0 +1,54 @@
+package graphql_test
+
+import (
+	"testing"
+
+	"github.com/KarpelesLab/graphql"
+)
+
+func TestRewrite(t *testing.T) {
+	doc, err := graphql.Parse(`query Q { hero(episode: $ep) { name secret } droid { name } }`)
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+	orig := doc.String()
+
+	res := graphql.Rewrite(doc, &graphql.Rewriter{
+		Selection: func(c *graphql.Cursor, s graphql.Selection) graphql.Selection {
+			if f, ok := s.(*graphql.Field); ok && f.Name == "secret" {
+				return nil
+			}
+			return s
+		},
+		Argument: func(c *graphql.Cursor, a *graphql.Argument) *graphql.Argument {
+			if a.Name == "episode" {
+				return &graphql.Argument{Name: "ep", Value: a.Value}
+			}
+			return a
+		},
+		Value: func(c *graphql.Cursor, v graphql.Value) graphql.Value {
+			if vv, ok := v.(*graphql.VariableValue); ok && vv.Var == "ep" {
+				return graphql.EnumValue("JEDI")
+			}
+			return v
+		},
+	})
+
+	if doc.String() != orig {
+		t.Errorf("original document was modified: %s", doc)
+	}
+	op := res.Operations["Q"]
+	hero := op.SelectionSet[0].(*graphql.Field)
+	if len(hero.SelectionSet) != 1 || hero.Arguments["ep"] != graphql.EnumValue("JEDI") || hero.Arguments["episode"] != nil {
+		t.Errorf("unexpected rewritten hero %s", hero)
+	}
+	if op.SelectionSet[1] != doc.Operations["Q"].SelectionSet[1] {
+		t.Errorf("unchanged droid field was not shared")
+	}
+
+	typed := graphql.AddTypename(res)
+	droid := typed.Operations["Q"].SelectionSet[1].(*graphql.Field)
+	if len(typed.Operations["Q"].SelectionSet) != 2 || len(droid.SelectionSet) != 2 || droid.SelectionSet[1].(*graphql.Field).Name != "__typename" {
+		t.Errorf("unexpected result of AddTypename: %s", typed)
+	}
+}
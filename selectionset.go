@@ -3,22 +3,12 @@ package graphql
 import (
 	"errors"
 	"fmt"
-	"strings"
 )
 
 type SelectionSet []Selection
 
 func (s SelectionSet) String() string {
-	if s == nil {
-		return ""
-	}
-
-	var t []string
-	for _, sub := range s {
-		t = append(t, sub.String())
-	}
-
-	return "{" + strings.Join(t, " ") + "}"
+	return PrintCompact(s)
 }
 
 type Selection interface {
@@ -43,38 +33,38 @@ func (p *Parser) parseSelectionSet() (SelectionSet, error) {
 			if err := p.skip(3); err != nil {
 				return nil, unexpected(err)
 			}
-			if !p.isName() {
-				if p.cur() == '{' {
-					// that's an inline fragment
-					sl, err := p.parseSelectionSet() // yay for recursion
+			var cond *TypeCondition
+			if p.isName() {
+				frag := p.readName()
+				if frag != "on" {
+					// FragmentSpread
+					dir, err := p.parseDirectives()
 					if err != nil {
 						return nil, err
 					}
-					res = append(res, &InlineFragment{SelectionSet: sl})
+					res = append(res, &FragmentSpread{Name: frag, Directives: dir})
 					continue
 				}
-				return nil, errors.New("... in a selection set must be followed by a name or a {")
-			}
-			frag := p.readName()
-			if frag == "on" {
-				cond, err := p.readTypeCondition() // we already have the "on"
-				if err != nil {
-					return nil, err
-				}
-				// InlineFragment
-				sl, err := p.parseSelectionSet() // yay for recursion
+				c, err := p.readTypeCondition() // we already have the "on"
 				if err != nil {
 					return nil, err
 				}
-				res = append(res, &InlineFragment{TypeCondition: cond, SelectionSet: sl})
+				cond = c
 			}
 
-			// FragmentSpread
+			// InlineFragment
 			dir, err := p.parseDirectives()
 			if err != nil {
 				return nil, err
 			}
-			res = append(res, &FragmentSpread{Name: frag, Directives: dir})
+			if p.cur() != '{' {
+				return nil, errors.New("... in a selection set must be followed by a name, a type condition, directives or a {")
+			}
+			sl, err := p.parseSelectionSet() // yay for recursion
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, &InlineFragment{TypeCondition: cond, Directives: dir, SelectionSet: sl})
 			continue
 		}
 
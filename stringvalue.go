@@ -11,8 +11,7 @@ import (
 type StringValue string
 
 func (s StringValue) String() string {
-	// escape
-	return "\"" + strconv.Quote(string(s)) + "\""
+	return PrintCompact(s)
 }
 
 func (s StringValue) MarshalJSON() ([]byte, error) {
@@ -48,7 +47,7 @@ func (p *Parser) parseStringValue() (Value, error) {
 			p.nextNotSpace()
 			return StringValue(buf.String()), nil
 		}
-		if c == '\n' {
+		if c == '\n' || c == '\r' {
 			// error
 			return nil, errors.New("string value cannot contain LineTerminator")
 		}
@@ -76,10 +75,14 @@ func (p *Parser) parseStringValue() (Value, error) {
 			case 'u':
 				// read unicode
 				// \u[0-9A-Fa-f]{4}
+				if err := p.next(); err != nil {
+					return nil, unexpected(err)
+				}
 				uv, err := p.take(4)
 				if err != nil {
 					return nil, err
 				}
+				p.pos -= 1 // stay on the last digit, loop will move forward
 				// need to parse hex value ([0-9a-fA-F])
 				v, err := strconv.ParseUint(uv, 16, 32)
 				if err != nil {
@@ -89,6 +92,7 @@ func (p *Parser) parseStringValue() (Value, error) {
 			default:
 				return nil, fmt.Errorf("invalid escape sequence in StringValue: \\%c", c)
 			}
+			continue
 		}
 
 		buf.WriteByte(c)
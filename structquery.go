@@ -0,0 +1,408 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// NewOperation builds an operation from the shape of v, which must be a
+// struct or a pointer to a struct.
+//
+// Each exported struct field becomes a Field named after the Go field with
+// its first letter lowercased, unless a graphql tag is present in which case
+// the tag is parsed as a field, e.g. `graphql:"luke: human(id: $id)"`.
+// Embedded structs are flattened, or become an InlineFragment when tagged
+// with `graphql:"... on Type"`. Fields tagged `graphql:"-"` are ignored.
+//
+// Variable definitions are derived from the Go types of the values in
+// variables: pointers are nullable, slices are lists, and named types keep
+// their Go name (type Episode string becomes Episode!). Every variable
+// referenced in the tags must be present in variables, and v must not be of a
+// recursive type.
+func NewOperation(typ OperationType, name string, v any, variables map[string]any) (*Operation, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a struct to build operation, got %T", v)
+	}
+
+	sl, err := structSelectionSet(t, make(map[reflect.Type]bool))
+	if err != nil {
+		return nil, err
+	}
+	defs, err := variableDefinitionsOf(variables)
+	if err != nil {
+		return nil, err
+	}
+
+	op := &Operation{
+		OperationType:       typ,
+		Name:                name,
+		VariableDefinitions: defs,
+		SelectionSet:        sl,
+	}
+	if err := checkVariablesDefined(op, variables); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+// checkVariablesDefined returns an error if op references a variable that is
+// not in variables, as it would be sent without a definition
+func checkVariablesDefined(op *Operation, variables map[string]any) error {
+	var err error
+	Walk(&Visitor{
+		EnterValue: func(c *Cursor, v Value) WalkAction {
+			vv, ok := v.(*VariableValue)
+			if !ok {
+				return WalkContinue
+			}
+			if _, found := variables[vv.Var]; !found {
+				err = fmt.Errorf("variable $%s is used but has no value to derive its type from", vv.Var)
+				return WalkStop
+			}
+			return WalkContinue
+		},
+	}, op)
+	return err
+}
+
+// isLeafType returns true if values of type t are decoded as a whole and do
+// not need a selection set
+func isLeafType(t reflect.Type) bool {
+	if t.Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return isLeafType(t.Elem())
+	case reflect.Struct:
+		return false
+	default:
+		return true
+	}
+}
+
+// structSelectionSet returns the selection set for struct type t. visiting
+// holds the types currently being expanded, so recursive types are reported
+// instead of expanded forever.
+func structSelectionSet(t reflect.Type, visiting map[reflect.Type]bool) (SelectionSet, error) {
+	if visiting[t] {
+		return nil, fmt.Errorf("recursive type %s cannot be turned into a selection set", t)
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	var res SelectionSet
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, hasTag := sf.Tag.Lookup("graphql")
+		if tag == "-" || (!sf.IsExported() && !sf.Anonymous) {
+			continue
+		}
+		ft := sf.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		if sf.Anonymous && !hasTag {
+			if ft.Kind() != reflect.Struct {
+				continue
+			}
+			// flatten embedded struct
+			sub, err := structSelectionSet(ft, visiting)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, sub...)
+			continue
+		}
+
+		sel, err := parseFieldTag(sf, tag)
+		if err != nil {
+			return nil, err
+		}
+
+		switch s := sel.(type) {
+		case *InlineFragment:
+			if ft.Kind() != reflect.Struct {
+				return nil, fmt.Errorf("field %s: inline fragment must be a struct", sf.Name)
+			}
+			s.SelectionSet, err = structSelectionSet(ft, visiting)
+			if err != nil {
+				return nil, err
+			}
+		case *Field:
+			if s.SelectionSet == nil && !isLeafType(sf.Type) {
+				for ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array || ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				s.SelectionSet, err = structSelectionSet(ft, visiting)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		res = append(res, sel)
+	}
+	return res, nil
+}
+
+// parseFieldTag returns the selection described by a struct field and its
+// graphql tag, either a *Field or an *InlineFragment
+func parseFieldTag(sf reflect.StructField, tag string) (Selection, error) {
+	if tag == "" {
+		return &Field{Name: lowerName(sf.Name)}, nil
+	}
+
+	p := &Parser{str: tag}
+	if err := p.skipSpaces(); err != nil {
+		return nil, fmt.Errorf("field %s: empty graphql tag", sf.Name)
+	}
+
+	var res Selection
+	if p.is("...") {
+		if err := p.skip(3); err != nil {
+			return nil, fmt.Errorf("field %s: %w", sf.Name, unexpected(err))
+		}
+		cond, err := p.parseTypeCondition()
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", sf.Name, err)
+		}
+		dir, err := p.parseDirectives()
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", sf.Name, err)
+		}
+		res = &InlineFragment{TypeCondition: cond, Directives: dir}
+	} else {
+		f, err := p.parseField()
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", sf.Name, err)
+		}
+		res = f
+	}
+
+	if !p.eof() {
+		return nil, fmt.Errorf("field %s: unexpected %c in graphql tag", sf.Name, p.cur())
+	}
+	return res, nil
+}
+
+// responseKey returns the key under which the value of sf is found in a
+// response, as selected by NewOperation
+func responseKey(sf reflect.StructField) (string, error) {
+	tag := sf.Tag.Get("graphql")
+	sel, err := parseFieldTag(sf, tag)
+	if err != nil {
+		return "", err
+	}
+	f, ok := sel.(*Field)
+	if !ok {
+		return "", nil
+	}
+	if f.Alias != "" {
+		return f.Alias, nil
+	}
+	return f.Name, nil
+}
+
+// lowerName converts a Go field name into a GraphQL field name, turning
+// "Name" into "name" and "URLPath" into "urlPath"
+func lowerName(n string) string {
+	r := []rune(n)
+	for i := 0; i < len(r) && unicode.IsUpper(r[i]); i++ {
+		if i > 0 && i+1 < len(r) && unicode.IsLower(r[i+1]) {
+			break
+		}
+		r[i] = unicode.ToLower(r[i])
+	}
+	return string(r)
+}
+
+func variableDefinitionsOf(variables map[string]any) (VariableDefinitions, error) {
+	if len(variables) == 0 {
+		return nil, nil
+	}
+	names := make([]string, 0, len(variables))
+	for k := range variables {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var res VariableDefinitions
+	for _, k := range names {
+		t := reflect.TypeOf(variables[k])
+		if t == nil {
+			return nil, fmt.Errorf("cannot derive type of variable $%s from nil", k)
+		}
+		typ, err := goTypeName(t)
+		if err != nil {
+			return nil, fmt.Errorf("variable $%s: %w", k, err)
+		}
+		res = append(res, &VariableDefinition{Variable: k, Type: typ})
+	}
+	return res, nil
+}
+
+// goTypeName returns the GraphQL type matching Go type t
+func goTypeName(t reflect.Type) (string, error) {
+	if t.Kind() == reflect.Pointer {
+		typ, err := goTypeName(t.Elem())
+		return strings.TrimSuffix(typ, "!"), err
+	}
+	if t.Name() != "" && t.PkgPath() != "" {
+		// named type, keep the name
+		return t.Name() + "!", nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "String!", nil
+	case reflect.Bool:
+		return "Boolean!", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "Int!", nil
+	case reflect.Float32, reflect.Float64:
+		return "Float!", nil
+	case reflect.Slice, reflect.Array:
+		typ, err := goTypeName(t.Elem())
+		return "[" + typ + "]!", err
+	default:
+		return "", fmt.Errorf("cannot derive a GraphQL type from %s", t)
+	}
+}
+
+// unmarshalStruct decodes the data of a response into v, matching response
+// keys the same way NewOperation built the selection for v
+func unmarshalStruct(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("decode target must be a non-nil pointer")
+	}
+	return decodeValue(data, rv.Elem())
+}
+
+func decodeValue(data []byte, v reflect.Value) error {
+	if isLeafType(v.Type()) {
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if string(data) == "null" {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decodeValue(data, v.Elem())
+	case reflect.Slice:
+		var list []json.RawMessage
+		if err := json.Unmarshal(data, &list); err != nil {
+			return err
+		}
+		if list == nil {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		s := reflect.MakeSlice(v.Type(), len(list), len(list))
+		for n, sub := range list {
+			if err := decodeValue(sub, s.Index(n)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	case reflect.Array:
+		var list []json.RawMessage
+		if err := json.Unmarshal(data, &list); err != nil {
+			return err
+		}
+		for n := 0; n < len(list) && n < v.Len(); n++ {
+			if err := decodeValue(list[n], v.Index(n)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		if string(data) == "null" {
+			return nil
+		}
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+		return decodeStruct(obj, v)
+	default:
+		return fmt.Errorf("cannot decode into %s", v.Type())
+	}
+}
+
+func decodeStruct(obj map[string]json.RawMessage, v reflect.Value) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, hasTag := sf.Tag.Lookup("graphql")
+		if tag == "-" || (!sf.IsExported() && !sf.Anonymous) {
+			continue
+		}
+		fv := v.Field(i)
+
+		if sf.Anonymous && !hasTag {
+			// flattened struct, fields are at the same level
+			if err := decodeInline(obj, fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		key, err := responseKey(sf)
+		if err != nil {
+			return err
+		}
+		if key == "" {
+			// inline fragment, fields are at the same level
+			if err := decodeInline(obj, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		data, ok := obj[key]
+		if !ok {
+			continue
+		}
+		if err := decodeValue(data, fv); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func decodeInline(obj map[string]json.RawMessage, v reflect.Value) error {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			if !v.CanSet() {
+				return nil
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	return decodeStruct(obj, v)
+}
@@ -0,0 +1,119 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KarpelesLab/graphql"
+)
+
+type Episode string
+
+type character struct {
+	Name  string
+	Droid struct {
+		PrimaryFunction string
+	} `graphql:"... on Droid"`
+}
+
+func TestNewOperation(t *testing.T) {
+	var q struct {
+		Hero struct {
+			character
+			Friends []*struct {
+				Name string
+			} `graphql:"friends @include(if: $withFriends)"`
+		} `graphql:"hero(episode: $ep)"`
+		LukeHuman struct {
+			Height float64
+		} `graphql:"luke: human(id: $id)"`
+		Ignored string `graphql:"-"`
+	}
+	vars := map[string]any{
+		"ep":          Episode("JEDI"),
+		"id":          (*string)(nil),
+		"withFriends": true,
+	}
+
+	op, err := graphql.NewOperation(graphql.Query, "Hero", &q, vars)
+	if err != nil {
+		t.Fatalf("failed to build operation: %s", err)
+	}
+	if len(op.VariableDefinitions) != 3 || op.VariableDefinitions[0].Type != "Episode!" || op.VariableDefinitions[1].Type != "String" || op.VariableDefinitions[2].Type != "Boolean!" {
+		t.Errorf("unexpected variable definitions %s", op.VariableDefinitions)
+	}
+	hero := op.SelectionSet[0].(*graphql.Field)
+	if hero.Name != "hero" || hero.Arguments["episode"].String() != "$ep" || len(hero.SelectionSet) != 3 {
+		t.Errorf("unexpected hero field %s", hero)
+	}
+	if frag, ok := hero.SelectionSet[1].(*graphql.InlineFragment); !ok || frag.TypeCondition.NamedType != "Droid" {
+		t.Errorf("expected an inline fragment on Droid, got %s", hero.SelectionSet[1])
+	}
+	if luke := op.SelectionSet[1].(*graphql.Field); luke.Alias != "luke" || luke.Name != "human" {
+		t.Errorf("unexpected luke field %s", luke)
+	}
+	if len(op.SelectionSet) != 2 {
+		t.Errorf("unexpected selection in %s", op)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Variables["ep"] != "JEDI" || req.Variables["id"] != "1000" {
+			t.Errorf("unexpected variables %v", req.Variables)
+		}
+		const expected = `query ($ep: Episode!, $id: String!, $withFriends: Boolean!) {hero(episode: $ep) {name ... on Droid {primaryFunction} friends @include(if: $withFriends) {name}} luke: human(id: $id) {height}}`
+		if req.Query != expected {
+			t.Errorf("unexpected query %s", req.Query)
+		}
+		if _, err := graphql.Parse(req.Query); err != nil {
+			t.Errorf("sent query does not parse: %s", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"hero":{"name":"R2-D2","primaryFunction":"Astromech","friends":[{"name":"Luke"}]},"luke":{"height":1.72}}}`))
+	}))
+	defer srv.Close()
+
+	err = graphql.NewClient(srv.URL, srv.Client()).Exec(context.Background(), graphql.Query, &q, map[string]any{"ep": Episode("JEDI"), "id": "1000", "withFriends": true})
+	if err != nil {
+		t.Fatalf("exec failed: %s", err)
+	}
+	if q.Hero.Name != "R2-D2" || q.Hero.Droid.PrimaryFunction != "Astromech" || len(q.Hero.Friends) != 1 || q.Hero.Friends[0].Name != "Luke" || q.LukeHuman.Height != 1.72 {
+		t.Errorf("unexpected decoded value %+v", q)
+	}
+}
+
+func TestNewOperationErrors(t *testing.T) {
+	var q struct {
+		Luke struct {
+			Height float64
+		} `graphql:"luke: human(id: $id)"`
+	}
+	if _, err := graphql.NewOperation(graphql.Query, "", &q, nil); err == nil {
+		t.Errorf("expected an error for undeclared variable $id")
+	}
+
+	type Node struct {
+		Name     string
+		Children []Node
+	}
+	var r struct {
+		Root Node
+	}
+	if _, err := graphql.NewOperation(graphql.Query, "", &r, nil); err == nil {
+		t.Errorf("expected an error for recursive type")
+	}
+
+	// the same type may appear several times as long as it does not recurse
+	type Ref struct{ ID string }
+	var s struct {
+		A Ref
+		B []Ref
+	}
+	if _, err := graphql.NewOperation(graphql.Query, "", &s, nil); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
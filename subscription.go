@@ -0,0 +1,380 @@
+package graphql
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	maxRetryDelay         = 30 * time.Second
+	connectionInitTimeout = 10 * time.Second
+)
+
+// Transport selects the protocol used by Client.Subscribe.
+type Transport int
+
+const (
+	TransportSSE       Transport = iota // graphql-sse, distinct connections mode
+	TransportWebSocket                  // graphql-transport-ws
+)
+
+// errRetry is returned by the stream methods when a subscription can be
+// re-established
+var errRetry = errors.New("subscription stream interrupted")
+
+// Subscribe runs a subscription and returns a channel receiving each event.
+// Client.Transport selects between graphql-sse in distinct connections mode
+// and graphql-transport-ws.
+//
+// If the stream is interrupted before the server completes it, the
+// subscription is sent again after a delay starting at Client.RetryDelay and
+// doubling on each failed attempt. The channel is closed once the server
+// completes the subscription, once ctx is cancelled (which sends complete
+// over graphql-transport-ws, and closes the connection, the way graphql-sse
+// completes from the client side), or after a Response holding a non
+// recoverable error was delivered.
+func (c *Client) Subscribe(ctx context.Context, req *Request) (<-chan *Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan *Response)
+
+	go func() {
+		defer close(ch)
+
+		delay := c.RetryDelay
+		if delay <= 0 {
+			delay = time.Second
+		}
+		retry := delay
+
+		for {
+			var n int
+			var err error
+			switch c.Transport {
+			case TransportWebSocket:
+				n, err = c.streamWebSocket(ctx, body, ch)
+			default:
+				n, err = c.streamSSE(ctx, body, ch)
+			}
+			if err == nil || ctx.Err() != nil {
+				return
+			}
+			if !errors.Is(err, errRetry) {
+				select {
+				case ch <- &Response{Errors: errorsOf(err)}:
+				case <-ctx.Done():
+				}
+				return
+			}
+			if n > 0 {
+				// the stream worked for a while, start over with a short delay
+				retry = delay
+			}
+
+			t := time.NewTimer(retry)
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				t.Stop()
+				return
+			}
+			retry *= 2
+			if retry > maxRetryDelay {
+				retry = maxRetryDelay
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// errorsOf returns err as a GraphQL errors array
+func errorsOf(err error) Errors {
+	var rerr *ResponseError
+	if errors.As(err, &rerr) {
+		return rerr.Errors
+	}
+	return Errors{&Error{Message: err.Error()}}
+}
+
+// streamSSE runs a single subscription request over graphql-sse and sends
+// its events to ch, returning the number of events received. A nil error
+// means the server completed the subscription.
+func (c *Client) streamSSE(ctx context.Context, body []byte, ch chan<- *Response) (int, error) {
+	hreq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	hreq.Header.Set("Content-Type", "application/json")
+	hreq.Header.Set("Accept", "text/event-stream")
+
+	h := c.HTTP
+	if h == nil {
+		h = http.DefaultClient
+	}
+	resp, err := h.Do(hreq)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", errRetry, err)
+	}
+	defer resp.Body.Close()
+
+	ct, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if ct != "text/event-stream" {
+		if resp.StatusCode >= 500 {
+			return 0, fmt.Errorf("%w: HTTP status %s", errRetry, resp.Status)
+		}
+		// the server refused the subscription, it might have said why
+		res, err := readResponse(resp)
+		if err != nil {
+			return 0, err
+		}
+		if len(res.Errors) == 0 {
+			return 0, errors.New("server did not start an event stream")
+		}
+		return 0, &ResponseError{Errors: res.Errors, Data: res.Data}
+	}
+
+	n := 0
+	r := bufio.NewReader(resp.Body)
+	var event string
+	var data []string
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF || ctx.Err() == nil {
+				return n, fmt.Errorf("%w: %s", errRetry, unexpected(err))
+			}
+			return n, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if line != "" {
+			// field: value
+			if line[0] == ':' {
+				// comment, used for keep-alive
+				continue
+			}
+			k, v, _ := strings.Cut(line, ":")
+			v = strings.TrimPrefix(v, " ")
+			switch k {
+			case "event":
+				event = v
+			case "data":
+				data = append(data, v)
+			}
+			continue
+		}
+
+		// empty line, dispatch event
+		switch event {
+		case "next":
+			res := &Response{}
+			if err := json.Unmarshal([]byte(strings.Join(data, "\n")), res); err != nil {
+				return n, fmt.Errorf("failed to decode event: %w", err)
+			}
+			n += 1
+			select {
+			case ch <- res:
+			case <-ctx.Done():
+				return n, ctx.Err()
+			}
+		case "complete":
+			return n, nil
+		}
+		event = ""
+		data = nil
+	}
+}
+
+// wsMessage is a graphql-transport-ws message
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// streamWebSocket runs a single subscription over graphql-transport-ws, with
+// the same semantics as streamSSE. body is the JSON encoded Request, sent as
+// the payload of the subscribe message.
+func (c *Client) streamWebSocket(ctx context.Context, body []byte, ch chan<- *Response) (int, error) {
+	ws, err := c.dialWebSocket(ctx, "graphql-transport-ws")
+	if err != nil {
+		return 0, err
+	}
+	defer ws.rw.Close()
+
+	send := func(m *wsMessage) error {
+		buf, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		return ws.write(wsText, buf)
+	}
+	recv := func() (*wsMessage, error) {
+		buf, err := ws.read()
+		if err != nil {
+			return nil, err
+		}
+		m := &wsMessage{}
+		if err := json.Unmarshal(buf, m); err != nil {
+			return nil, fmt.Errorf("failed to decode message: %w", err)
+		}
+		return m, nil
+	}
+	// retryable returns err wrapped in errRetry unless the server closed the
+	// connection with a 44xx code, which means it will not accept us again
+	retryable := func(err error) error {
+		var cerr *wsCloseError
+		if errors.As(err, &cerr) && cerr.Code >= 4400 && cerr.Code < 4500 {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("%w: %s", errRetry, unexpected(err))
+	}
+
+	// the server has a limited time to acknowledge the connection
+	t := time.AfterFunc(connectionInitTimeout, func() { ws.rw.Close() })
+	if err := send(&wsMessage{Type: "connection_init"}); err != nil {
+		t.Stop()
+		return 0, retryable(err)
+	}
+	for {
+		m, err := recv()
+		if err != nil {
+			t.Stop()
+			return 0, retryable(err)
+		}
+		if m.Type == "connection_ack" {
+			break
+		}
+		if m.Type == "ping" {
+			send(&wsMessage{Type: "pong"})
+		}
+	}
+	t.Stop()
+
+	const id = "1"
+	if err := send(&wsMessage{ID: id, Type: "subscribe", Payload: body}); err != nil {
+		return 0, retryable(err)
+	}
+
+	// complete the subscription if ctx is cancelled
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			send(&wsMessage{ID: id, Type: "complete"})
+			ws.close()
+		case <-done:
+		}
+	}()
+
+	n := 0
+	for {
+		m, err := recv()
+		if err != nil {
+			return n, retryable(err)
+		}
+		switch m.Type {
+		case "next":
+			if m.ID != id {
+				continue
+			}
+			res := &Response{}
+			if err := json.Unmarshal(m.Payload, res); err != nil {
+				return n, fmt.Errorf("failed to decode event: %w", err)
+			}
+			n += 1
+			select {
+			case ch <- res:
+			case <-ctx.Done():
+				return n, ctx.Err()
+			}
+		case "error":
+			if m.ID != id {
+				continue
+			}
+			var errs Errors
+			if err := json.Unmarshal(m.Payload, &errs); err != nil {
+				return n, fmt.Errorf("failed to decode error: %w", err)
+			}
+			return n, &ResponseError{Errors: errs}
+		case "complete":
+			if m.ID == id {
+				ws.close()
+				return n, nil
+			}
+		case "ping":
+			if err := send(&wsMessage{Type: "pong"}); err != nil {
+				return n, retryable(err)
+			}
+		}
+	}
+}
+
+// Event is a subscription event whose data was decoded into a T.
+type Event[T any] struct {
+	Data       *T // nil if the event carried no data
+	Errors     Errors
+	Extensions map[string]any
+}
+
+// SubscribeEvents runs a subscription like Client.Subscribe and decodes the
+// data of each event into a new T, matching response keys the same way
+// Client.Exec does. If req.Query is empty, the subscription is built from the
+// shape of T with NewOperation, using req.Variables.
+//
+// An event whose data cannot be decoded is still delivered, with the reason
+// appended to its Errors.
+func SubscribeEvents[T any](ctx context.Context, c *Client, req *Request) (<-chan *Event[T], error) {
+	if req.Query == "" {
+		op, err := NewOperation(Subscription, req.OperationName, new(T), req.Variables)
+		if err != nil {
+			return nil, err
+		}
+		req = &Request{Query: PrintCompact(op), OperationName: req.OperationName, Variables: req.Variables}
+	}
+
+	src, err := c.Subscribe(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan *Event[T])
+
+	go func() {
+		defer close(ch)
+
+		for res := range src {
+			ev := &Event[T]{Errors: res.Errors, Extensions: res.Extensions}
+			if len(res.Data) > 0 && string(res.Data) != "null" {
+				ev.Data = new(T)
+				if err := unmarshalStruct(res.Data, ev.Data); err != nil {
+					ev.Errors = append(ev.Errors, &Error{Message: "failed to decode event data: " + err.Error()})
+				}
+			}
+			select {
+			case ch <- ev:
+			case <-ctx.Done():
+				// src is closed by Subscribe on its own
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
@@ -0,0 +1,346 @@
+package graphql_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KarpelesLab/graphql"
+)
+
+func TestSubscribe(t *testing.T) {
+	var conns int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("unexpected Accept header %q", r.Header.Get("Accept"))
+		}
+		n := atomic.AddInt32(&conns, 1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, ": keep-alive\n\n")
+		fmt.Fprintf(w, "event: next\ndata: {\"data\":{\"count\":%d}}\n\n", n)
+		if n == 1 {
+			// drop the connection without completing
+			return
+		}
+		fmt.Fprintf(w, "event: complete\ndata:\n\n")
+	}))
+	defer srv.Close()
+
+	c := graphql.NewClient(srv.URL, srv.Client())
+	c.RetryDelay = time.Millisecond
+
+	ch, err := c.Subscribe(context.Background(), &graphql.Request{Query: `subscription { count }`})
+	if err != nil {
+		t.Fatalf("subscribe failed: %s", err)
+	}
+
+	var events []string
+	for ev := range ch {
+		if len(ev.Errors) > 0 {
+			t.Fatalf("unexpected error event: %s", ev.Errors)
+		}
+		events = append(events, string(ev.Data))
+	}
+	if len(events) != 2 || events[0] != `{"count":1}` || events[1] != `{"count":2}` {
+		t.Errorf("unexpected events %v", events)
+	}
+	if conns != 2 {
+		t.Errorf("expected 2 connections, got %d", conns)
+	}
+}
+
+func TestSubscribeEvents(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Query != `subscription {c: count}` {
+			t.Errorf("unexpected query %s", req.Query)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "event: next\ndata: {\"data\":{\"c\":1}}\n\n")
+		fmt.Fprintf(w, "event: next\ndata: {\"data\":{\"c\":\"two\"}}\n\n")
+		fmt.Fprintf(w, "event: complete\ndata:\n\n")
+	}))
+	defer srv.Close()
+
+	type counter struct {
+		Count int `graphql:"c: count"`
+	}
+	ch, err := graphql.SubscribeEvents[counter](context.Background(), graphql.NewClient(srv.URL, srv.Client()), &graphql.Request{})
+	if err != nil {
+		t.Fatalf("subscribe failed: %s", err)
+	}
+
+	var events []*graphql.Event[counter]
+	for ev := range ch {
+		events = append(events, ev)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0].Data == nil || events[0].Data.Count != 1 || len(events[0].Errors) != 0 {
+		t.Errorf("unexpected first event %+v", events[0])
+	}
+	if len(events[1].Errors) != 1 {
+		t.Errorf("expected a decode error in second event, got %+v", events[1])
+	}
+}
+
+// wsHandler returns a handler accepting graphql-transport-ws connections and
+// passing them to fn once upgraded
+func wsHandler(t *testing.T, fn func(conn net.Conn, rw *bufio.ReadWriter)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "websocket" || r.Header.Get("Sec-WebSocket-Protocol") != "graphql-transport-ws" {
+			t.Errorf("unexpected upgrade request headers %v", r.Header)
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("hijack failed: %s", err)
+			return
+		}
+		defer conn.Close()
+		fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+		fmt.Fprintf(rw, "Sec-WebSocket-Accept: %s\r\nSec-WebSocket-Protocol: graphql-transport-ws\r\n\r\n", base64.StdEncoding.EncodeToString(sum[:]))
+		rw.Flush()
+		fn(conn, rw)
+	})
+}
+
+type wsTestMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// wsRead reads a masked client frame and decodes it as a message. Close
+// frames are returned as a message of type "close".
+func wsRead(r *bufio.Reader) (*wsTestMessage, error) {
+	var hdr [2]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, err
+	}
+	n := int(hdr[1] & 0x7f)
+	if n == 126 {
+		var b [2]byte
+		io.ReadFull(r, b[:])
+		n = int(binary.BigEndian.Uint16(b[:]))
+	}
+	var key [4]byte
+	io.ReadFull(r, key[:])
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	for i := range data {
+		data[i] ^= key[i%4]
+	}
+	switch hdr[0] & 0x0f {
+	case 0x8:
+		return &wsTestMessage{Type: "close"}, nil
+	case 0xa:
+		return &wsTestMessage{Type: "pong frame"}, nil
+	}
+	m := &wsTestMessage{}
+	return m, json.Unmarshal(data, m)
+}
+
+// wsWrite sends data in an unmasked server frame
+func wsWrite(w *bufio.Writer, op byte, data string) {
+	w.Write([]byte{0x80 | op, byte(len(data))})
+	w.WriteString(data)
+	w.Flush()
+}
+
+// wsAccept runs the connection_init and subscribe part of the protocol and
+// returns the id of the subscription
+func wsAccept(t *testing.T, rw *bufio.ReadWriter) string {
+	m, err := wsRead(rw.Reader)
+	if err != nil || m.Type != "connection_init" {
+		t.Errorf("expected connection_init, got %v %v", m, err)
+	}
+	wsWrite(rw.Writer, 0x1, `{"type":"connection_ack"}`)
+	m, err = wsRead(rw.Reader)
+	if err != nil || m.Type != "subscribe" {
+		t.Errorf("expected subscribe, got %v %v", m, err)
+		return ""
+	}
+	var req graphql.Request
+	json.Unmarshal(m.Payload, &req)
+	if req.Query != `subscription { count }` {
+		t.Errorf("unexpected query %s", req.Query)
+	}
+	return m.ID
+}
+
+func TestSubscribeWebSocket(t *testing.T) {
+	var conns int32
+
+	srv := httptest.NewServer(wsHandler(t, func(conn net.Conn, rw *bufio.ReadWriter) {
+		n := atomic.AddInt32(&conns, 1)
+		id := wsAccept(t, rw)
+		if n == 1 {
+			wsWrite(rw.Writer, 0x1, `{"id":"`+id+`","type":"next","payload":{"data":{"count":1}}}`)
+			// drop the connection without completing
+			return
+		}
+		// protocol ping, then a WebSocket ping
+		wsWrite(rw.Writer, 0x1, `{"type":"ping"}`)
+		if m, err := wsRead(rw.Reader); err != nil || m.Type != "pong" {
+			t.Errorf("expected pong, got %v %v", m, err)
+		}
+		wsWrite(rw.Writer, 0x9, "hi")
+		if m, err := wsRead(rw.Reader); err != nil || m.Type != "pong frame" {
+			t.Errorf("expected pong frame, got %v %v", m, err)
+		}
+		wsWrite(rw.Writer, 0x1, `{"id":"`+id+`","type":"next","payload":{"data":{"count":2}}}`)
+		wsWrite(rw.Writer, 0x1, `{"id":"`+id+`","type":"complete"}`)
+		wsRead(rw.Reader)
+	}))
+	defer srv.Close()
+
+	c := graphql.NewClient(srv.URL, srv.Client())
+	c.RetryDelay = time.Millisecond
+	c.Transport = graphql.TransportWebSocket
+
+	ch, err := c.Subscribe(context.Background(), &graphql.Request{Query: `subscription { count }`})
+	if err != nil {
+		t.Fatalf("subscribe failed: %s", err)
+	}
+
+	var events []string
+	for ev := range ch {
+		if len(ev.Errors) > 0 {
+			t.Fatalf("unexpected error event: %s", ev.Errors)
+		}
+		events = append(events, string(ev.Data))
+	}
+	if len(events) != 2 || events[0] != `{"count":1}` || events[1] != `{"count":2}` {
+		t.Errorf("unexpected events %v", events)
+	}
+	if conns != 2 {
+		t.Errorf("expected 2 connections, got %d", conns)
+	}
+}
+
+func TestSubscribeWebSocketCancel(t *testing.T) {
+	completed := make(chan string, 1)
+
+	srv := httptest.NewServer(wsHandler(t, func(conn net.Conn, rw *bufio.ReadWriter) {
+		id := wsAccept(t, rw)
+		wsWrite(rw.Writer, 0x1, `{"id":"`+id+`","type":"next","payload":{"data":{"count":1}}}`)
+		m, err := wsRead(rw.Reader)
+		if err != nil {
+			t.Errorf("failed to read complete: %s", err)
+			return
+		}
+		if m.Type == "complete" && m.ID == id {
+			completed <- m.ID
+		}
+	}))
+	defer srv.Close()
+
+	c := graphql.NewClient(srv.URL, srv.Client())
+	c.Transport = graphql.TransportWebSocket
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := c.Subscribe(ctx, &graphql.Request{Query: `subscription { count }`})
+	if err != nil {
+		t.Fatalf("subscribe failed: %s", err)
+	}
+	if ev := <-ch; ev == nil || string(ev.Data) != `{"count":1}` {
+		t.Fatalf("unexpected event %v", ev)
+	}
+	cancel()
+
+	select {
+	case <-completed:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("server did not receive complete")
+	}
+	for range ch {
+	}
+}
+
+// TestSubscribeWebSocketFrames checks that frames breaking RFC 6455 make the
+// client drop the connection and reconnect, and that fragmented messages
+// are assembled.
+func TestSubscribeWebSocketFrames(t *testing.T) {
+	next := `{"id":"1","type":"next","payload":{"data":{"count":1}}}`
+	for name, frames := range map[string]string{
+		"overflowing continuation": "\x01\x02{\"" + "\x80\x7f\xff\xff\xff\xff\xff\xff\xff\xff",
+		"length top bit":           "\x81\x7f\x80\x00\x00\x00\x00\x00\x00\x01",
+		"too large":                "\x81\x7f\x00\x00\x00\x00\x10\x00\x00\x00",
+		"masked":                   "\x81\x82\x00\x00\x00\x00{}",
+		"reserved bits":            "\xc1\x02{}",
+		"unknown opcode":           "\x83\x02{}",
+		"lone continuation":        "\x80\x02{}",
+		"interleaved message":      "\x01\x02{\"" + "\x81\x02{}",
+		"fragmented control":       "\x09\x00",
+		"long control":             "\x89\x7e\x00\x80",
+	} {
+		t.Run(name, func(t *testing.T) {
+			var conns int32
+			srv := httptest.NewServer(wsHandler(t, func(conn net.Conn, rw *bufio.ReadWriter) {
+				n := atomic.AddInt32(&conns, 1)
+				wsAccept(t, rw)
+				if n == 1 {
+					rw.WriteString(frames)
+					rw.Flush()
+					// the client must drop the connection
+					io.Copy(io.Discard, rw)
+					return
+				}
+				// fragmented message with a ping in the middle
+				rw.Write([]byte{0x01, byte(len(next) - 10)})
+				rw.WriteString(next[:len(next)-10])
+				rw.WriteString("\x89\x00")
+				rw.Write([]byte{0x80, 10})
+				rw.WriteString(next[len(next)-10:])
+				rw.Flush()
+				if m, err := wsRead(rw.Reader); err != nil || m.Type != "pong frame" {
+					t.Errorf("expected pong frame, got %v %v", m, err)
+				}
+				wsWrite(rw.Writer, 0x1, `{"id":"1","type":"complete"}`)
+				wsRead(rw.Reader)
+			}))
+			defer srv.Close()
+
+			c := graphql.NewClient(srv.URL, srv.Client())
+			c.RetryDelay = time.Millisecond
+			c.Transport = graphql.TransportWebSocket
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			ch, err := c.Subscribe(ctx, &graphql.Request{Query: `subscription { count }`})
+			if err != nil {
+				t.Fatalf("subscribe failed: %s", err)
+			}
+			var events []string
+			for ev := range ch {
+				if len(ev.Errors) > 0 {
+					t.Fatalf("unexpected error event: %s", ev.Errors)
+				}
+				events = append(events, string(ev.Data))
+			}
+			if len(events) != 1 || events[0] != `{"count":1}` || conns != 2 {
+				t.Errorf("unexpected events %v over %d connections", events, conns)
+			}
+		})
+	}
+}
@@ -7,7 +7,7 @@ type TypeCondition struct {
 }
 
 func (t *TypeCondition) String() string {
-	return "on " + t.NamedType
+	return PrintCompact(t)
 }
 
 func (p *Parser) parseTypeCondition() (*TypeCondition, error) {
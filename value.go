@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 )
 
 // https://spec.graphql.org/June2018/#Value
@@ -74,6 +75,66 @@ func (v NullValue) MarshalJSON() ([]byte, error) {
 	return json.Marshal(res)
 }
 
+// IntValue holds an integer literal as written in the document, so that
+// values of any size are kept as is.
+type IntValue string
+
+func (v IntValue) String() string {
+	return string(v)
+}
+
+func (v IntValue) MarshalJSON() ([]byte, error) {
+	res := map[string]any{
+		"type":  "int_value",
+		"value": json.Number(v),
+	}
+	return json.Marshal(res)
+}
+
+// FloatValue holds a float literal as written in the document, so that its
+// precision is kept as is.
+type FloatValue string
+
+func (v FloatValue) String() string {
+	return string(v)
+}
+
+func (v FloatValue) MarshalJSON() ([]byte, error) {
+	res := map[string]any{
+		"type":  "float_value",
+		"value": json.Number(v),
+	}
+	return json.Marshal(res)
+}
+
+type ListValue []Value
+
+func (v ListValue) String() string {
+	return PrintCompact(v)
+}
+
+func (v ListValue) MarshalJSON() ([]byte, error) {
+	res := map[string]any{
+		"type":   "list_value",
+		"values": []Value(v),
+	}
+	return json.Marshal(res)
+}
+
+type ObjectValue map[string]Value
+
+func (v ObjectValue) String() string {
+	return PrintCompact(v)
+}
+
+func (v ObjectValue) MarshalJSON() ([]byte, error) {
+	res := map[string]any{
+		"type":   "object_value",
+		"fields": map[string]Value(v),
+	}
+	return json.Marshal(res)
+}
+
 func (p *Parser) parseValue() (Value, error) {
 	// can be a number of things...
 	switch p.cur() {
@@ -87,6 +148,17 @@ func (p *Parser) parseValue() (Value, error) {
 		return &VariableValue{Var: p.readName()}, nil
 	case '"':
 		return p.parseStringValue()
+	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return p.parseNumberValue()
+	case '[':
+		return p.parseListValue()
+	case '{':
+		return p.parseObjectValue()
+	case 0:
+		if p.eof() {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, errors.New("unsupported value character NUL")
 	default:
 		if p.isName() {
 			nam := p.readName()
@@ -104,3 +176,117 @@ func (p *Parser) parseValue() (Value, error) {
 		return nil, fmt.Errorf("unsupported value character %c", p.cur())
 	}
 }
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func (p *Parser) parseNumberValue() (Value, error) {
+	// IntValue :: IntegerPart
+	// FloatValue :: IntegerPart FractionalPart? ExponentPart?
+	start := p.pos
+	isFloat := false
+
+	if p.cur() == '-' {
+		p.next()
+	}
+	if !isDigit(p.cur()) {
+		return nil, fmt.Errorf("expected a digit in number, got a %c", p.cur())
+	}
+	if p.cur() == '0' {
+		p.next()
+		if isDigit(p.cur()) {
+			return nil, errors.New("number cannot have leading zeroes")
+		}
+	}
+	for isDigit(p.cur()) {
+		p.next()
+	}
+	if p.cur() == '.' {
+		isFloat = true
+		p.next()
+		if !isDigit(p.cur()) {
+			return nil, fmt.Errorf("expected a digit after decimal point, got a %c", p.cur())
+		}
+		for isDigit(p.cur()) {
+			p.next()
+		}
+	}
+	if p.cur() == 'e' || p.cur() == 'E' {
+		isFloat = true
+		p.next()
+		if p.cur() == '+' || p.cur() == '-' {
+			p.next()
+		}
+		if !isDigit(p.cur()) {
+			return nil, fmt.Errorf("expected a digit in exponent, got a %c", p.cur())
+		}
+		for isDigit(p.cur()) {
+			p.next()
+		}
+	}
+	if p.isName() || p.cur() == '.' {
+		return nil, fmt.Errorf("invalid character %c after number", p.cur())
+	}
+	num := p.str[start:p.pos]
+	p.skipSpaces()
+
+	if isFloat {
+		return FloatValue(num), nil
+	}
+	return IntValue(num), nil
+}
+
+func (p *Parser) parseListValue() (Value, error) {
+	// at this point p.cur() == '['
+	if err := p.nextNotSpace(); err != nil {
+		return nil, unexpected(err)
+	}
+
+	res := ListValue{}
+	for {
+		if p.cur() == ']' {
+			p.nextNotSpace()
+			return res, nil
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+	}
+}
+
+func (p *Parser) parseObjectValue() (Value, error) {
+	// at this point p.cur() == '{'
+	if err := p.nextNotSpace(); err != nil {
+		return nil, unexpected(err)
+	}
+
+	res := make(ObjectValue)
+	for {
+		if p.cur() == '}' {
+			p.nextNotSpace()
+			return res, nil
+		}
+		// expect: name: value
+		if !p.isName() {
+			return nil, fmt.Errorf("expected name in object value but got a %c", p.cur())
+		}
+		name := p.readName()
+		if p.cur() != ':' {
+			return nil, fmt.Errorf("expected a colon after name, got a %c", p.cur())
+		}
+		if err := p.nextNotSpace(); err != nil {
+			return nil, unexpected(err)
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := res[name]; ok {
+			return nil, fmt.Errorf("duplicate field %s in object value", name)
+		}
+		res[name] = v
+	}
+}
@@ -11,13 +11,14 @@ type VariableDefinition struct {
 	DefaultValue Value // optional
 }
 
+func (v *VariableDefinition) String() string {
+	return PrintCompact(v)
+}
+
 type VariableDefinitions []*VariableDefinition
 
 func (v VariableDefinitions) String() string {
-	if v == nil {
-		return ""
-	}
-	return "TODO VariableDefinitions"
+	return PrintCompact(v)
 }
 
 func (p *Parser) parseVariableDefinitions() (VariableDefinitions, error) {
@@ -28,6 +29,13 @@ func (p *Parser) parseVariableDefinitions() (VariableDefinitions, error) {
 		return nil, unexpected(err)
 	}
 
+	if err := p.skipSpaces(); err != nil {
+		return nil, unexpected(err)
+	}
+	if p.cur() == ')' {
+		return nil, fmt.Errorf("variable definitions must not be empty")
+	}
+
 	var res VariableDefinitions
 
 	for {
@@ -45,8 +53,64 @@ func (p *Parser) parseVariableDefinitions() (VariableDefinitions, error) {
 		if name == "" {
 			return nil, fmt.Errorf("variable name must be a name")
 		}
+		if p.cur() != ':' {
+			return nil, fmt.Errorf("expected a colon after variable $%s, got a %c", name, p.cur())
+		}
+		if err := p.nextNotSpace(); err != nil {
+			return nil, unexpected(err)
+		}
+		typ, err := p.parseType()
+		if err != nil {
+			return nil, fmt.Errorf("in variable $%s: %w", name, err)
+		}
+		def := &VariableDefinition{Variable: name, Type: typ}
 
-		return nil, errors.New("TODO VariableDefinitions")
+		if p.cur() == '=' {
+			if err := p.nextNotSpace(); err != nil {
+				return nil, unexpected(err)
+			}
+			def.DefaultValue, err = p.parseValue()
+			if err != nil {
+				return nil, fmt.Errorf("in default value of $%s: %w", name, err)
+			}
+		}
+		res = append(res, def)
+	}
+}
+
+// parseType reads a Type and returns it as it would be written, for example
+// "[String!]!"
+func (p *Parser) parseType() (string, error) {
+	// Type :: NamedType | ListType | NonNullType
+	var res string
 
+	if p.cur() == '[' {
+		if err := p.nextNotSpace(); err != nil {
+			return "", unexpected(err)
+		}
+		sub, err := p.parseType()
+		if err != nil {
+			return "", err
+		}
+		if p.cur() != ']' {
+			return "", fmt.Errorf("expected ] at end of list type, got a %c", p.cur())
+		}
+		if err := p.nextNotSpace(); err != nil {
+			return "", unexpected(err)
+		}
+		res = "[" + sub + "]"
+	} else {
+		if !p.isName() {
+			return "", errors.New("expected a type name")
+		}
+		res = p.readName()
+	}
+
+	if p.cur() == '!' {
+		if err := p.nextNotSpace(); err != nil {
+			return "", unexpected(err)
+		}
+		res += "!"
 	}
+	return res, nil
 }
@@ -0,0 +1,386 @@
+package graphql
+
+import "sort"
+
+// WalkAction tells Walk how to proceed after a callback.
+type WalkAction int
+
+const (
+	WalkContinue WalkAction = iota // continue walking
+	WalkSkip                       // when entering a node, do not visit its children nor leave it
+	WalkStop                       // stop walking entirely
+)
+
+// Argument is a single argument of a field or directive, as visited by Walk.
+type Argument struct {
+	Name  string
+	Value Value
+}
+
+func (a *Argument) String() string {
+	return PrintCompact(a)
+}
+
+// Visitor holds the callbacks invoked by Walk. Each node kind has an Enter
+// callback called before its children are visited and a Leave callback
+// called after. Any callback may be nil.
+//
+// Maps (operations, fragments, arguments) are visited in name order so that
+// walking the same document always happens in the same order.
+type Visitor struct {
+	EnterDocument           func(c *Cursor, doc *Document) WalkAction
+	LeaveDocument           func(c *Cursor, doc *Document) WalkAction
+	EnterOperation          func(c *Cursor, op *Operation) WalkAction
+	LeaveOperation          func(c *Cursor, op *Operation) WalkAction
+	EnterFragment           func(c *Cursor, f *Fragment) WalkAction
+	LeaveFragment           func(c *Cursor, f *Fragment) WalkAction
+	EnterVariableDefinition func(c *Cursor, v *VariableDefinition) WalkAction
+	LeaveVariableDefinition func(c *Cursor, v *VariableDefinition) WalkAction
+	EnterSelectionSet       func(c *Cursor, s SelectionSet) WalkAction
+	LeaveSelectionSet       func(c *Cursor, s SelectionSet) WalkAction
+	EnterField              func(c *Cursor, f *Field) WalkAction
+	LeaveField              func(c *Cursor, f *Field) WalkAction
+	EnterInlineFragment     func(c *Cursor, f *InlineFragment) WalkAction
+	LeaveInlineFragment     func(c *Cursor, f *InlineFragment) WalkAction
+	EnterFragmentSpread     func(c *Cursor, f *FragmentSpread) WalkAction
+	LeaveFragmentSpread     func(c *Cursor, f *FragmentSpread) WalkAction
+	EnterDirective          func(c *Cursor, d *Directive) WalkAction
+	LeaveDirective          func(c *Cursor, d *Directive) WalkAction
+	EnterArgument           func(c *Cursor, a *Argument) WalkAction
+	LeaveArgument           func(c *Cursor, a *Argument) WalkAction
+	EnterValue              func(c *Cursor, v Value) WalkAction
+	LeaveValue              func(c *Cursor, v Value) WalkAction
+}
+
+// Cursor describes the position of the node being visited.
+type Cursor struct {
+	nodes []any // ancestors, followed by the current node
+	path  []any
+}
+
+// Node returns the node being visited.
+func (c *Cursor) Node() any {
+	return c.nodes[len(c.nodes)-1]
+}
+
+// Parent returns the parent of the node being visited, or nil for the node
+// Walk was called with.
+func (c *Cursor) Parent() any {
+	if len(c.nodes) < 2 {
+		return nil
+	}
+	return c.nodes[len(c.nodes)-2]
+}
+
+// Ancestors returns the ancestors of the node being visited, starting with
+// the node Walk was called with.
+func (c *Cursor) Ancestors() []any {
+	return append([]any(nil), c.nodes[:len(c.nodes)-1]...)
+}
+
+// Path returns the keys leading from the node Walk was called with to the
+// node being visited, e.g. ["operations", "Q", "selection_set", 0]. Keys are
+// the JSON names of the traversed fields, followed by names (string) in maps
+// or indices (int) in lists.
+func (c *Cursor) Path() []any {
+	return append([]any(nil), c.path...)
+}
+
+// Depth returns the number of ancestors of the node being visited.
+func (c *Cursor) Depth() int {
+	return len(c.nodes) - 1
+}
+
+// Walk traverses node depth-first, invoking the callbacks of v. node can be
+// a *Document, *Operation, *Fragment, *VariableDefinition, SelectionSet,
+// Selection, *Directive, *Argument or Value. It returns false if a callback
+// stopped the walk.
+func Walk(v *Visitor, node any) bool {
+	w := &walker{v: v}
+	return w.walk(node)
+}
+
+type walker struct {
+	v *Visitor
+	c Cursor
+}
+
+// walk visits node under the given keys in its parent and returns false if
+// the walk was stopped
+func (w *walker) walk(node any, keys ...any) bool {
+	if len(keys) > 0 {
+		l := len(w.c.path)
+		w.c.path = append(w.c.path, keys...)
+		defer func() { w.c.path = w.c.path[:l] }()
+	}
+	w.c.nodes = append(w.c.nodes, node)
+	defer func() { w.c.nodes = w.c.nodes[:len(w.c.nodes)-1] }()
+
+	switch w.enter(node) {
+	case WalkStop:
+		return false
+	case WalkSkip:
+		// children and leave callback are skipped
+		return true
+	}
+	if !w.children(node) {
+		return false
+	}
+	return w.leave(node) != WalkStop
+}
+
+func (w *walker) children(node any) bool {
+	switch n := node.(type) {
+	case *Document:
+		for _, k := range sortedKeys(n.Operations) {
+			if !w.walk(n.Operations[k], "operations", k) {
+				return false
+			}
+		}
+		for _, k := range sortedKeys(n.Fragments) {
+			if !w.walk(n.Fragments[k], "fragments", k) {
+				return false
+			}
+		}
+	case *Operation:
+		for i, d := range n.VariableDefinitions {
+			if !w.walk(d, "variable_definitions", i) {
+				return false
+			}
+		}
+		return w.directives(n.Directives) && w.selectionSet(n.SelectionSet)
+	case *Fragment:
+		return w.directives(n.Directives) && w.selectionSet(n.SelectionSet)
+	case *VariableDefinition:
+		if n.DefaultValue != nil {
+			return w.walk(n.DefaultValue, "default_value")
+		}
+	case SelectionSet:
+		for i, s := range n {
+			if !w.walk(s, i) {
+				return false
+			}
+		}
+	case *Field:
+		return w.arguments(n.Arguments) && w.directives(n.Directives) && w.selectionSet(n.SelectionSet)
+	case *InlineFragment:
+		return w.directives(n.Directives) && w.selectionSet(n.SelectionSet)
+	case *FragmentSpread:
+		return w.directives(n.Directives)
+	case *Directive:
+		return w.arguments(n.Arguments)
+	case *Argument:
+		return w.walk(n.Value, "value")
+	case ListValue:
+		for i, v := range n {
+			if !w.walk(v, i) {
+				return false
+			}
+		}
+	case ObjectValue:
+		for _, k := range sortedKeys(n) {
+			if !w.walk(n[k], k) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (w *walker) selectionSet(s SelectionSet) bool {
+	if s == nil {
+		return true
+	}
+	return w.walk(s, "selection_set")
+}
+
+func (w *walker) directives(ds Directives) bool {
+	for i, d := range ds {
+		if !w.walk(d, "directives", i) {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *walker) arguments(args Arguments) bool {
+	for _, k := range sortedKeys(args) {
+		if !w.walk(&Argument{Name: k, Value: args[k]}, "arguments", k) {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *walker) enter(node any) WalkAction {
+	v, c := w.v, &w.c
+	switch n := node.(type) {
+	case *Document:
+		return call(v.EnterDocument, c, n)
+	case *Operation:
+		return call(v.EnterOperation, c, n)
+	case *Fragment:
+		return call(v.EnterFragment, c, n)
+	case *VariableDefinition:
+		return call(v.EnterVariableDefinition, c, n)
+	case SelectionSet:
+		return call(v.EnterSelectionSet, c, n)
+	case *Field:
+		return call(v.EnterField, c, n)
+	case *InlineFragment:
+		return call(v.EnterInlineFragment, c, n)
+	case *FragmentSpread:
+		return call(v.EnterFragmentSpread, c, n)
+	case *Directive:
+		return call(v.EnterDirective, c, n)
+	case *Argument:
+		return call(v.EnterArgument, c, n)
+	case Value:
+		return call(v.EnterValue, c, n)
+	}
+	return WalkContinue
+}
+
+func (w *walker) leave(node any) WalkAction {
+	v, c := w.v, &w.c
+	switch n := node.(type) {
+	case *Document:
+		return call(v.LeaveDocument, c, n)
+	case *Operation:
+		return call(v.LeaveOperation, c, n)
+	case *Fragment:
+		return call(v.LeaveFragment, c, n)
+	case *VariableDefinition:
+		return call(v.LeaveVariableDefinition, c, n)
+	case SelectionSet:
+		return call(v.LeaveSelectionSet, c, n)
+	case *Field:
+		return call(v.LeaveField, c, n)
+	case *InlineFragment:
+		return call(v.LeaveInlineFragment, c, n)
+	case *FragmentSpread:
+		return call(v.LeaveFragmentSpread, c, n)
+	case *Directive:
+		return call(v.LeaveDirective, c, n)
+	case *Argument:
+		return call(v.LeaveArgument, c, n)
+	case Value:
+		return call(v.LeaveValue, c, n)
+	}
+	return WalkContinue
+}
+
+func call[T any](f func(*Cursor, T) WalkAction, c *Cursor, n T) WalkAction {
+	if f == nil {
+		return WalkContinue
+	}
+	return f(c, n)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	res := make([]string, 0, len(m))
+	for k := range m {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// ParallelVisitor returns a visitor running all of vs in a single walk.
+// A visitor returning WalkSkip only skips the subtree for itself, and a
+// visitor returning WalkStop is no longer called while the others carry on.
+// The walk stops once every visitor has stopped. The returned visitor can be
+// reused, each walk starts with all of vs active again.
+func ParallelVisitor(vs ...*Visitor) *Visitor {
+	p := &parallelVisitor{vs: vs, skipping: make([]int, len(vs)), stopped: make([]bool, len(vs))}
+	p.reset()
+
+	return &Visitor{
+		EnterDocument:           parallelEnter(p, func(v *Visitor) func(*Cursor, *Document) WalkAction { return v.EnterDocument }),
+		LeaveDocument:           parallelLeave(p, func(v *Visitor) func(*Cursor, *Document) WalkAction { return v.LeaveDocument }),
+		EnterOperation:          parallelEnter(p, func(v *Visitor) func(*Cursor, *Operation) WalkAction { return v.EnterOperation }),
+		LeaveOperation:          parallelLeave(p, func(v *Visitor) func(*Cursor, *Operation) WalkAction { return v.LeaveOperation }),
+		EnterFragment:           parallelEnter(p, func(v *Visitor) func(*Cursor, *Fragment) WalkAction { return v.EnterFragment }),
+		LeaveFragment:           parallelLeave(p, func(v *Visitor) func(*Cursor, *Fragment) WalkAction { return v.LeaveFragment }),
+		EnterVariableDefinition: parallelEnter(p, func(v *Visitor) func(*Cursor, *VariableDefinition) WalkAction { return v.EnterVariableDefinition }),
+		LeaveVariableDefinition: parallelLeave(p, func(v *Visitor) func(*Cursor, *VariableDefinition) WalkAction { return v.LeaveVariableDefinition }),
+		EnterSelectionSet:       parallelEnter(p, func(v *Visitor) func(*Cursor, SelectionSet) WalkAction { return v.EnterSelectionSet }),
+		LeaveSelectionSet:       parallelLeave(p, func(v *Visitor) func(*Cursor, SelectionSet) WalkAction { return v.LeaveSelectionSet }),
+		EnterField:              parallelEnter(p, func(v *Visitor) func(*Cursor, *Field) WalkAction { return v.EnterField }),
+		LeaveField:              parallelLeave(p, func(v *Visitor) func(*Cursor, *Field) WalkAction { return v.LeaveField }),
+		EnterInlineFragment:     parallelEnter(p, func(v *Visitor) func(*Cursor, *InlineFragment) WalkAction { return v.EnterInlineFragment }),
+		LeaveInlineFragment:     parallelLeave(p, func(v *Visitor) func(*Cursor, *InlineFragment) WalkAction { return v.LeaveInlineFragment }),
+		EnterFragmentSpread:     parallelEnter(p, func(v *Visitor) func(*Cursor, *FragmentSpread) WalkAction { return v.EnterFragmentSpread }),
+		LeaveFragmentSpread:     parallelLeave(p, func(v *Visitor) func(*Cursor, *FragmentSpread) WalkAction { return v.LeaveFragmentSpread }),
+		EnterDirective:          parallelEnter(p, func(v *Visitor) func(*Cursor, *Directive) WalkAction { return v.EnterDirective }),
+		LeaveDirective:          parallelLeave(p, func(v *Visitor) func(*Cursor, *Directive) WalkAction { return v.LeaveDirective }),
+		EnterArgument:           parallelEnter(p, func(v *Visitor) func(*Cursor, *Argument) WalkAction { return v.EnterArgument }),
+		LeaveArgument:           parallelLeave(p, func(v *Visitor) func(*Cursor, *Argument) WalkAction { return v.LeaveArgument }),
+		EnterValue:              parallelEnter(p, func(v *Visitor) func(*Cursor, Value) WalkAction { return v.EnterValue }),
+		LeaveValue:              parallelLeave(p, func(v *Visitor) func(*Cursor, Value) WalkAction { return v.LeaveValue }),
+	}
+}
+
+type parallelVisitor struct {
+	vs       []*Visitor
+	skipping []int // depth of the node each visitor is skipping, or -1
+	stopped  []bool
+}
+
+// reset clears the state left by a previous walk
+func (p *parallelVisitor) reset() {
+	for i := range p.vs {
+		p.skipping[i] = -1
+		p.stopped[i] = false
+	}
+}
+
+func (p *parallelVisitor) result() WalkAction {
+	for _, s := range p.stopped {
+		if !s {
+			return WalkContinue
+		}
+	}
+	return WalkStop
+}
+
+func parallelEnter[T any](p *parallelVisitor, get func(*Visitor) func(*Cursor, T) WalkAction) func(*Cursor, T) WalkAction {
+	return func(c *Cursor, n T) WalkAction {
+		if c.Depth() == 0 {
+			// entering the root node, a new walk starts
+			p.reset()
+		}
+		for i, v := range p.vs {
+			if p.stopped[i] || p.skipping[i] >= 0 {
+				continue
+			}
+			switch call(get(v), c, n) {
+			case WalkSkip:
+				p.skipping[i] = c.Depth()
+			case WalkStop:
+				p.stopped[i] = true
+			}
+		}
+		return p.result()
+	}
+}
+
+func parallelLeave[T any](p *parallelVisitor, get func(*Visitor) func(*Cursor, T) WalkAction) func(*Cursor, T) WalkAction {
+	return func(c *Cursor, n T) WalkAction {
+		for i, v := range p.vs {
+			if p.stopped[i] {
+				continue
+			}
+			if p.skipping[i] >= 0 {
+				if p.skipping[i] == c.Depth() {
+					p.skipping[i] = -1
+				}
+				continue
+			}
+			if call(get(v), c, n) == WalkStop {
+				p.stopped[i] = true
+			}
+		}
+		return p.result()
+	}
+}
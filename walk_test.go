@@ -0,0 +1,92 @@
+package graphql_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/KarpelesLab/graphql"
+)
+
+func TestWalk(t *testing.T) {
+	doc, err := graphql.Parse(`query Q { hero(episode: JEDI) { name friends { name } ...F } }
+fragment F on Character { id @include(if: $withID) }`)
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+
+	var trace []string
+	graphql.Walk(&graphql.Visitor{
+		EnterField: func(c *graphql.Cursor, f *graphql.Field) graphql.WalkAction {
+			trace = append(trace, fmt.Sprintf("enter %s %v", f.Name, c.Path()))
+			if f.Name == "friends" {
+				return graphql.WalkSkip
+			}
+			return graphql.WalkContinue
+		},
+		LeaveField: func(c *graphql.Cursor, f *graphql.Field) graphql.WalkAction {
+			trace = append(trace, "leave "+f.Name)
+			return graphql.WalkContinue
+		},
+		EnterArgument: func(c *graphql.Cursor, a *graphql.Argument) graphql.WalkAction {
+			if p, ok := c.Parent().(*graphql.Field); a.Name == "episode" && (!ok || p.Name != "hero") {
+				t.Errorf("unexpected parent for argument %s: %v", a, c.Parent())
+			}
+			trace = append(trace, "argument "+a.String())
+			return graphql.WalkContinue
+		},
+		EnterValue: func(c *graphql.Cursor, v graphql.Value) graphql.WalkAction {
+			trace = append(trace, "value "+v.String())
+			return graphql.WalkContinue
+		},
+	}, doc)
+
+	expect := []string{
+		"enter hero [operations Q selection_set 0]",
+		"argument episode: JEDI",
+		"value JEDI",
+		"enter name [operations Q selection_set 0 selection_set 0]",
+		"leave name",
+		"enter friends [operations Q selection_set 0 selection_set 1]",
+		"leave hero",
+		"enter id [fragments F selection_set 0]",
+		"argument if: $withID",
+		"value $withID",
+		"leave id",
+	}
+	if strings.Join(trace, "\n") != strings.Join(expect, "\n") {
+		t.Errorf("unexpected walk:\n%s", strings.Join(trace, "\n"))
+	}
+
+	// two visitors in parallel, one of them stopping early
+	var fields, spreads int
+	ok := graphql.Walk(graphql.ParallelVisitor(
+		&graphql.Visitor{
+			EnterField: func(c *graphql.Cursor, f *graphql.Field) graphql.WalkAction {
+				fields += 1
+				return graphql.WalkStop
+			},
+		},
+		&graphql.Visitor{
+			EnterFragmentSpread: func(c *graphql.Cursor, f *graphql.FragmentSpread) graphql.WalkAction {
+				spreads += 1
+				return graphql.WalkContinue
+			},
+		},
+	), doc)
+	if !ok || fields != 1 || spreads != 1 {
+		t.Errorf("unexpected parallel walk result ok=%v fields=%d spreads=%d", ok, fields, spreads)
+	}
+
+	// a stopped visitor runs again when the parallel visitor is reused
+	fields, spreads = 0, 0
+	pv := graphql.ParallelVisitor(&graphql.Visitor{
+		EnterField: func(c *graphql.Cursor, f *graphql.Field) graphql.WalkAction {
+			fields += 1
+			return graphql.WalkStop
+		},
+	})
+	if graphql.Walk(pv, doc) || graphql.Walk(pv, doc) || fields != 2 {
+		t.Errorf("unexpected reused parallel walk result fields=%d", fields)
+	}
+}
@@ -0,0 +1,240 @@
+package graphql
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// WebSocket opcodes, see RFC 6455 section 5.2
+const (
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xa
+)
+
+const (
+	wsGUID           = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	wsMaxMessageSize = 32 << 20
+)
+
+// wsConn is the client side of a WebSocket connection, only implementing
+// what graphql-transport-ws needs
+type wsConn struct {
+	rw io.ReadWriteCloser
+	r  *bufio.Reader
+	mu sync.Mutex // protects writes
+}
+
+// wsCloseError is returned by wsConn.read when the peer closed the
+// connection
+type wsCloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *wsCloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("websocket closed with code %d", e.Code)
+	}
+	return fmt.Sprintf("websocket closed with code %d: %s", e.Code, e.Reason)
+}
+
+// dialWebSocket opens a WebSocket connection to the client's endpoint using
+// the given subprotocol. Endpoints can use either ws(s):// or http(s)://.
+func (c *Client) dialWebSocket(ctx context.Context, protocol string) (*wsConn, error) {
+	u, err := url.Parse(c.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	}
+
+	var k [16]byte
+	if _, err := rand.Read(k[:]); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(k[:])
+
+	hreq, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	hreq.Header.Set("Connection", "Upgrade")
+	hreq.Header.Set("Upgrade", "websocket")
+	hreq.Header.Set("Sec-WebSocket-Version", "13")
+	hreq.Header.Set("Sec-WebSocket-Key", key)
+	hreq.Header.Set("Sec-WebSocket-Protocol", protocol)
+
+	h := c.HTTP
+	if h == nil {
+		h = http.DefaultClient
+	}
+	resp, err := h.Do(hreq)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errRetry, err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		resp.Body.Close()
+		if resp.StatusCode >= 500 {
+			return nil, fmt.Errorf("%w: HTTP status %s", errRetry, resp.Status)
+		}
+		return nil, fmt.Errorf("server refused websocket upgrade: HTTP status %s", resp.Status)
+	}
+
+	rw, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		resp.Body.Close()
+		return nil, errors.New("websocket upgrade response body is not writable")
+	}
+	sum := sha1.Sum([]byte(key + wsGUID))
+	if resp.Header.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(sum[:]) {
+		rw.Close()
+		return nil, errors.New("invalid Sec-WebSocket-Accept in websocket upgrade response")
+	}
+	if p := resp.Header.Get("Sec-WebSocket-Protocol"); p != protocol {
+		rw.Close()
+		return nil, fmt.Errorf("server does not support websocket subprotocol %s", protocol)
+	}
+
+	return &wsConn{rw: rw, r: bufio.NewReader(rw)}, nil
+}
+
+// write sends data as a single masked frame
+func (ws *wsConn) write(op byte, data []byte) error {
+	var key [4]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		return err
+	}
+
+	buf := []byte{0x80 | op, 0x80}
+	switch {
+	case len(data) < 126:
+		buf[1] |= byte(len(data))
+	case len(data) <= 0xffff:
+		buf[1] |= 126
+		buf = binary.BigEndian.AppendUint16(buf, uint16(len(data)))
+	default:
+		buf[1] |= 127
+		buf = binary.BigEndian.AppendUint64(buf, uint64(len(data)))
+	}
+	buf = append(buf, key[:]...)
+	for i, b := range data {
+		buf = append(buf, b^key[i%4])
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	_, err := ws.rw.Write(buf)
+	return err
+}
+
+// read returns the next data message, assembling fragmented messages and
+// answering pings on the way. Frames breaking RFC 6455 are reported as
+// errors.
+func (ws *wsConn) read() ([]byte, error) {
+	var msg []byte
+	started := false
+
+	for {
+		var hdr [2]byte
+		if _, err := io.ReadFull(ws.r, hdr[:]); err != nil {
+			return nil, err
+		}
+		fin := hdr[0]&0x80 != 0
+		op := hdr[0] & 0x0f
+
+		if hdr[0]&0x70 != 0 {
+			return nil, errors.New("websocket frame has reserved bits set")
+		}
+		if hdr[1]&0x80 != 0 {
+			return nil, errors.New("websocket frame from server must not be masked")
+		}
+		switch op {
+		case wsText, wsBinary:
+			if started {
+				return nil, errors.New("websocket message started before the previous one ended")
+			}
+			started = true
+		case wsContinuation:
+			if !started {
+				return nil, errors.New("websocket continuation frame without a message")
+			}
+		case wsClose, wsPing, wsPong:
+			if !fin || hdr[1]&0x7f > 125 {
+				return nil, errors.New("websocket control frame must not be fragmented or longer than 125 bytes")
+			}
+		default:
+			return nil, fmt.Errorf("unexpected websocket opcode %d", op)
+		}
+
+		n := uint64(hdr[1] & 0x7f)
+		switch n {
+		case 126:
+			var b [2]byte
+			if _, err := io.ReadFull(ws.r, b[:]); err != nil {
+				return nil, unexpected(err)
+			}
+			n = uint64(binary.BigEndian.Uint16(b[:]))
+		case 127:
+			var b [8]byte
+			if _, err := io.ReadFull(ws.r, b[:]); err != nil {
+				return nil, unexpected(err)
+			}
+			n = binary.BigEndian.Uint64(b[:])
+			if n>>63 != 0 {
+				return nil, errors.New("websocket frame length has its most significant bit set")
+			}
+		}
+		if n > wsMaxMessageSize || uint64(len(msg)) > wsMaxMessageSize-n {
+			return nil, errors.New("websocket message too large")
+		}
+
+		data := make([]byte, n)
+		if _, err := io.ReadFull(ws.r, data); err != nil {
+			return nil, unexpected(err)
+		}
+
+		switch op {
+		case wsPing:
+			if err := ws.write(wsPong, data); err != nil {
+				return nil, err
+			}
+		case wsPong:
+		case wsClose:
+			res := &wsCloseError{Code: 1005}
+			if len(data) >= 2 {
+				res.Code = int(binary.BigEndian.Uint16(data))
+				res.Reason = string(data[2:])
+			}
+			return nil, res
+		default:
+			msg = append(msg, data...)
+			if fin {
+				return msg, nil
+			}
+		}
+	}
+}
+
+// close sends a normal closure frame and closes the connection
+func (ws *wsConn) close() error {
+	ws.write(wsClose, []byte{0x03, 0xe8}) // 1000
+	return ws.rw.Close()
+}